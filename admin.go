@@ -0,0 +1,217 @@
+package dpkg
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Diversion represents an entry of the diversions file of the dpkg database
+type Diversion struct {
+	Path     string // Path of the diverted file
+	DivertTo string // Path where the diverted file is installed instead
+	Package  string // Package allowed to install the file at Path, empty for local diversions
+}
+
+// Alternative represents a link group of update-alternatives
+type Alternative struct {
+	Name    string
+	Mode    string // "auto" or "manual"
+	Link    string // Path of the master link, e.g. /usr/bin/editor
+	Slaves  []AlternativeLink
+	Choices []AlternativeChoice
+	Current string // Target of /etc/alternatives/<name>, empty when it does not exist
+}
+
+// AlternativeLink represents a slave link of a link group
+type AlternativeLink struct {
+	Name string
+	Link string
+}
+
+// AlternativeChoice represents an alternative of a link group
+type AlternativeChoice struct {
+	Path     string
+	Priority int
+	Slaves   []string // Targets of the slave links, in the order of Alternative.Slaves, empty when not provided
+}
+
+// Diversions returns the diversions of the dpkg database, as set up by dpkg-divert. There
+// are none when the diversions file does not exist.
+func (d *Dpkg) Diversions() ([]Diversion, error) {
+	lines, err := readLines(d.adminPath("diversions"))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	if len(lines)%3 != 0 {
+		return nil, fmt.Errorf("go-apt/dpkg: truncated diversions file")
+	}
+
+	var diversions []Diversion
+	for i := 0; i < len(lines); i += 3 {
+		diversion := Diversion{Path: lines[i], DivertTo: lines[i+1], Package: lines[i+2]}
+		// Local diversions are owned by ":"
+		if diversion.Package == ":" {
+			diversion.Package = ""
+		}
+		diversions = append(diversions, diversion)
+	}
+	return diversions, nil
+}
+
+// Architectures returns the architectures listed in the arch file of the dpkg database,
+// the native one and those added with dpkg --add-architecture. It is empty when the arch
+// file does not exist.
+func (d *Dpkg) Architectures() ([]string, error) {
+	lines, err := readLines(d.adminPath("arch"))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	var archs []string
+	for _, line := range lines {
+		if arch := strings.TrimSpace(line); arch != "" {
+			archs = append(archs, arch)
+		}
+	}
+	return archs, nil
+}
+
+// Alternatives returns the link groups of update-alternatives, sorted by name
+func (d *Dpkg) Alternatives() ([]Alternative, error) {
+	entries, err := os.ReadDir(d.adminPath("alternatives"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var alternatives []Alternative
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		alternative, err := d.Alternative(entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		alternatives = append(alternatives, *alternative)
+	}
+	return alternatives, nil
+}
+
+// Alternative returns a link group of update-alternatives, read from the alternatives
+// directory of the dpkg database
+func (d *Dpkg) Alternative(name string) (*Alternative, error) {
+	if name == "" || strings.Contains(name, "/") || name == "." || name == ".." {
+		return nil, fmt.Errorf("go-apt/dpkg: invalid alternative name %q", name)
+	}
+
+	lines, err := readLines(d.adminPath("alternatives", name))
+	if err != nil {
+		return nil, err
+	}
+
+	alternative, err := parseAlternative(name, lines)
+	if err != nil {
+		return nil, err
+	}
+
+	// The current choice is the target of the symlink of the administrative directory
+	target, err := os.Readlink(filepath.Join(d.rootPath("/etc/alternatives"), name))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	alternative.Current = target
+
+	return alternative, nil
+}
+
+// parseAlternative parses the lines of a file of the alternatives directory: the mode and
+// the master link, pairs of slave name and link ended by an empty line, then for each
+// choice its path, its priority and the targets of the slave links, usually ended by an
+// empty line
+func parseAlternative(name string, lines []string) (*Alternative, error) {
+	invalid := fmt.Errorf("go-apt/dpkg: invalid alternatives file %s", name)
+
+	next := func() (string, bool) {
+		if len(lines) == 0 {
+			return "", false
+		}
+		line := lines[0]
+		lines = lines[1:]
+		return line, true
+	}
+
+	alternative := &Alternative{Name: name}
+	var ok bool
+	if alternative.Mode, ok = next(); !ok || (alternative.Mode != "auto" && alternative.Mode != "manual") {
+		return nil, invalid
+	}
+	if alternative.Link, ok = next(); !ok || alternative.Link == "" {
+		return nil, invalid
+	}
+
+	for {
+		slaveName, ok := next()
+		if !ok {
+			return nil, invalid
+		}
+		if slaveName == "" {
+			break
+		}
+		slaveLink, ok := next()
+		if !ok || slaveLink == "" {
+			return nil, invalid
+		}
+		alternative.Slaves = append(alternative.Slaves, AlternativeLink{Name: slaveName, Link: slaveLink})
+	}
+
+	for {
+		choicePath, ok := next()
+		if !ok || choicePath == "" {
+			break
+		}
+
+		choice := AlternativeChoice{Path: choicePath}
+		priority, ok := next()
+		if !ok {
+			return nil, invalid
+		}
+		var err error
+		if choice.Priority, err = strconv.Atoi(priority); err != nil {
+			return nil, invalid
+		}
+		for range alternative.Slaves {
+			slave, ok := next()
+			if !ok {
+				return nil, invalid
+			}
+			choice.Slaves = append(choice.Slaves, slave)
+		}
+		alternative.Choices = append(alternative.Choices, choice)
+	}
+
+	return alternative, nil
+}
+
+// readLines reads the lines of a file of the dpkg database
+func readLines(name string) ([]string, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines, scanner.Err()
+}
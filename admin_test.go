@@ -0,0 +1,98 @@
+package dpkg
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestDiversions tests the Diversions method of Dpkg
+func TestDiversions(t *testing.T) {
+	d := Dpkg{Root: "testdata/root"}
+	diversions, err := d.Diversions()
+	if err != nil {
+		t.Fatalf("Diversions failed: %v", err)
+	}
+	want := []Diversion{
+		{Path: "/bin/sh", DivertTo: "/bin/sh.distrib", Package: "dash"},
+		{Path: "/etc/issue", DivertTo: "/etc/issue.orig"},
+	}
+	if !reflect.DeepEqual(diversions, want) {
+		t.Errorf("Diversions() = %+v; want %+v", diversions, want)
+	}
+
+	d = Dpkg{Root: t.TempDir()}
+	if diversions, err := d.Diversions(); err != nil || diversions != nil {
+		t.Errorf("Diversions() = %+v, %v; want none without diversions file", diversions, err)
+	}
+}
+
+// TestArchitectures tests the Architectures method of Dpkg
+func TestArchitectures(t *testing.T) {
+	d := Dpkg{Root: "testdata/root"}
+	archs, err := d.Architectures()
+	if err != nil || !reflect.DeepEqual(archs, []string{"amd64", "i386"}) {
+		t.Errorf("Architectures() = %v, %v; want [amd64 i386]", archs, err)
+	}
+}
+
+// TestAlternatives tests the Alternatives method of Dpkg
+func TestAlternatives(t *testing.T) {
+	root := newTestRoot(t)
+	if err := os.MkdirAll(filepath.Join(root, "etc/alternatives"), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.Symlink("/usr/bin/vim.tiny", filepath.Join(root, "etc/alternatives/editor")); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+
+	d := Dpkg{Root: root}
+	alternatives, err := d.Alternatives()
+	if err != nil {
+		t.Fatalf("Alternatives failed: %v", err)
+	}
+	want := []Alternative{{
+		Name:   "editor",
+		Mode:   "auto",
+		Link:   "/usr/bin/editor",
+		Slaves: []AlternativeLink{{Name: "editor.1.gz", Link: "/usr/share/man/man1/editor.1.gz"}},
+		Choices: []AlternativeChoice{
+			{Path: "/bin/nano", Priority: 40, Slaves: []string{"/usr/share/man/man1/nano.1.gz"}},
+			{Path: "/usr/bin/vim.tiny", Priority: 15, Slaves: []string{""}},
+		},
+		Current: "/usr/bin/vim.tiny",
+	}}
+	if !reflect.DeepEqual(alternatives, want) {
+		t.Errorf("Alternatives() = %+v; want %+v", alternatives, want)
+	}
+
+	for _, name := range []string{"", "..", "../status", "missing"} {
+		if _, err := d.Alternative(name); err == nil {
+			t.Errorf("Alternative(%q) succeeded; want an error", name)
+		}
+	}
+}
+
+// TestParseAlternative tests the errors reported for invalid alternatives files
+func TestParseAlternative(t *testing.T) {
+	tests := [][]string{
+		{},
+		{"sometimes", "/usr/bin/editor", ""},
+		{"auto", "/usr/bin/editor"},
+		{"auto", "/usr/bin/editor", "editor.1.gz"},
+		{"auto", "/usr/bin/editor", "", "/bin/nano", "high"},
+		{"auto", "/usr/bin/editor", "editor.1.gz", "/usr/share/man/man1/editor.1.gz", "", "/bin/nano", "40"},
+	}
+
+	for _, lines := range tests {
+		if alternative, err := parseAlternative("editor", lines); err == nil {
+			t.Errorf("parseAlternative(%q) = %+v; want an error", lines, alternative)
+		}
+	}
+
+	alternative, err := parseAlternative("editor", []string{"manual", "/usr/bin/editor", "", "/bin/nano", "40"})
+	if err != nil || alternative.Mode != "manual" || len(alternative.Choices) != 1 {
+		t.Errorf("parseAlternative() without final empty line = %+v, %v", alternative, err)
+	}
+}
@@ -0,0 +1,600 @@
+package dpkg
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/blakesmith/ar"
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+// Compression represents the compression of the members of a built package
+type Compression int
+
+const (
+	CompressionXz Compression = iota
+	CompressionGzip
+	CompressionZstd
+	CompressionNone
+)
+
+var compressionNames = []string{"xz", "gzip", "zstd", "none"}
+
+// String returns the name of the compression, as used by dpkg-deb -Z
+func (c Compression) String() string {
+	return enumName(compressionNames, int(c))
+}
+
+// ParseCompression parses a compression name, as used by dpkg-deb -Z
+func ParseCompression(s string) (Compression, error) {
+	i, ok := enumIndex(compressionNames, s)
+	if !ok {
+		return 0, fmt.Errorf("go-apt/dpkg: unsupported compression %q", s)
+	}
+	return Compression(i), nil
+}
+
+// extension returns the extension of the tar members compressed with c
+func (c Compression) extension() string {
+	switch c {
+	case CompressionGzip:
+		return ".gz"
+	case CompressionZstd:
+		return ".zst"
+	case CompressionNone:
+		return ""
+	}
+	return ".xz"
+}
+
+// BuildFile represents an entry of the data archive of a package being built
+type BuildFile struct {
+	DataEntry
+	Data   []byte // Content of a regular file
+	Source string // Path of the file to read the content from, when Data is nil
+}
+
+// Builder builds a Debian package from a control paragraph and a list of files, like
+// dpkg-deb --build. The Installed-Size field and the md5sums control member are
+// computed when they are not provided.
+type Builder struct {
+	Control      *DebPackage
+	ControlFiles []ControlFile // Other control members, e.g. maintainer scripts or conffiles
+	Files        []BuildFile
+	Compression  Compression
+
+	// ModTime is the timestamp of the archive members, and the maximum modification time
+	// of the entries; it defaults to SOURCE_DATE_EPOCH when it is set
+	ModTime time.Time
+
+	// PreserveOwnership keeps the owner and group of the files, instead of root
+	PreserveOwnership bool
+}
+
+// NewBuilder creates a new Builder for the given control paragraph
+func NewBuilder(control *DebPackage) *Builder {
+	return &Builder{Control: control}
+}
+
+// NewBuilderFromDir creates a new Builder from a directory tree, where the DEBIAN
+// directory holds the control file and the other control members. The ownership of the
+// files is taken from the tree on Unix systems, for PreserveOwnership; the contents are
+// read when the package is built.
+func NewBuilderFromDir(root string) (*Builder, error) {
+	controlDir := filepath.Join(root, "DEBIAN")
+
+	file, err := os.Open(filepath.Join(controlDir, "control"))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrNoControlFile, err)
+	}
+	defer file.Close()
+
+	control, err := parseControlFile(file)
+	if err != nil {
+		return nil, err
+	}
+	b := NewBuilder(control)
+
+	controlEntries, err := os.ReadDir(controlDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range controlEntries {
+		if entry.Name() == "control" || !entry.Type().IsRegular() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		source := filepath.Join(controlDir, entry.Name())
+		b.ControlFiles = append(b.ControlFiles, ControlFile{Name: entry.Name(), Mode: info.Mode().Perm(), Source: source})
+	}
+
+	var owners ownerNames
+
+	err = filepath.WalkDir(root, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if filePath == controlDir {
+			return filepath.SkipDir
+		}
+
+		name, err := filepath.Rel(root, filePath)
+		if err != nil || name == "." {
+			return err
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		file := BuildFile{DataEntry: DataEntry{
+			Path:    filepath.ToSlash(name),
+			Mode:    info.Mode() & (fs.ModePerm | fs.ModeSetuid | fs.ModeSetgid | fs.ModeSticky),
+			ModTime: info.ModTime(),
+		}}
+		owners.setOwnership(&file.DataEntry, info)
+		switch {
+		case info.IsDir():
+			file.Type = EntryDirectory
+		case info.Mode().IsRegular():
+			file.Type = EntryRegular
+			file.Size = info.Size()
+			file.Source = filePath
+		case info.Mode()&fs.ModeSymlink != 0:
+			file.Type = EntrySymlink
+			if file.LinkTarget, err = os.Readlink(filePath); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("go-apt/dpkg: unsupported file type for %s", filePath)
+		}
+
+		b.Files = append(b.Files, file)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// Build writes the Debian package to w
+func (b *Builder) Build(w io.Writer) error {
+	for _, name := range []string{"Package", "Version", "Architecture"} {
+		if b.Control == nil || b.Control.Get(name) == "" {
+			return fmt.Errorf("%w: missing %s in control file", ErrInvalidField, name)
+		}
+	}
+
+	modTime, err := b.timestamp()
+	if err != nil {
+		return err
+	}
+
+	files, err := b.normalizeFiles()
+	if err != nil {
+		return err
+	}
+
+	data, installedSize, sums, err := b.buildDataArchive(files, modTime)
+	if err != nil {
+		return err
+	}
+	defer removeTempFile(data)
+
+	control, err := b.buildControlArchive(installedSize, sums, modTime)
+	if err != nil {
+		return err
+	}
+	defer removeTempFile(control)
+
+	// The timestamp of the ar members is the build time, unless it is fixed
+	if modTime.IsZero() {
+		modTime = time.Now()
+	}
+
+	arWriter := ar.NewWriter(w)
+	if err := arWriter.WriteGlobalHeader(); err != nil {
+		return err
+	}
+	header := &ar.Header{Name: "debian-binary", ModTime: modTime, Mode: 0644, Size: 4}
+	if err := arWriter.WriteHeader(header); err != nil {
+		return err
+	}
+	if _, err := arWriter.Write([]byte("2.0\n")); err != nil {
+		return err
+	}
+
+	members := []struct {
+		name string
+		file *os.File
+	}{
+		{"control.tar" + b.Compression.extension(), control},
+		{"data.tar" + b.Compression.extension(), data},
+	}
+	for _, member := range members {
+		info, err := member.file.Stat()
+		if err != nil {
+			return err
+		}
+		header := &ar.Header{Name: member.name, ModTime: modTime, Mode: 0644, Size: info.Size()}
+		if err := arWriter.WriteHeader(header); err != nil {
+			return err
+		}
+		// The ar writer pads odd members on each call, so the member is copied to w and
+		// padded here
+		if _, err := member.file.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		if _, err := io.CopyN(w, member.file, info.Size()); err != nil {
+			return err
+		}
+		if info.Size()%2 == 1 {
+			if _, err := w.Write([]byte{'\n'}); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// timestamp returns the fixed timestamp of the build, or the zero time
+func (b *Builder) timestamp() (time.Time, error) {
+	if !b.ModTime.IsZero() {
+		return b.ModTime, nil
+	}
+
+	epoch := os.Getenv("SOURCE_DATE_EPOCH")
+	if epoch == "" {
+		return time.Time{}, nil
+	}
+	seconds, err := strconv.ParseInt(epoch, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("go-apt/dpkg: invalid SOURCE_DATE_EPOCH %q", epoch)
+	}
+	return time.Unix(seconds, 0), nil
+}
+
+// normalizeFiles returns the files sorted by path, with cleaned paths and the missing
+// parent directories added
+func (b *Builder) normalizeFiles() ([]BuildFile, error) {
+	files := make(map[string]BuildFile)
+	for _, file := range b.Files {
+		name, err := cleanArchivePath(strings.TrimPrefix(file.Path, "/"))
+		if err != nil {
+			return nil, err
+		}
+		if name == "." {
+			continue
+		}
+		file.Path = name
+		files[name] = file
+	}
+
+	for name := range files {
+		for dir := path.Dir(name); dir != "."; dir = path.Dir(dir) {
+			if _, exists := files[dir]; !exists {
+				files[dir] = BuildFile{DataEntry: DataEntry{Path: dir, Type: EntryDirectory, Mode: 0755}}
+			}
+		}
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	// Hardlinks come last, so their targets are always extracted first
+	sorted := make([]BuildFile, 0, len(names))
+	for _, name := range names {
+		if files[name].Type != EntryHardlink {
+			sorted = append(sorted, files[name])
+		}
+	}
+	for _, name := range names {
+		if files[name].Type == EntryHardlink {
+			sorted = append(sorted, files[name])
+		}
+	}
+	return sorted, nil
+}
+
+// buildDataArchive writes the compressed data archive to a temporary file, and returns
+// it with the installed size in KiB and the md5sums of the regular files
+func (b *Builder) buildDataArchive(files []BuildFile, modTime time.Time) (*os.File, int64, map[string]string, error) {
+	sums := make(map[string]string)
+	var installedSize int64
+
+	archive, err := b.writeArchive(func(tarWriter *tar.Writer) error {
+		root := BuildFile{DataEntry: DataEntry{Path: ".", Type: EntryDirectory, Mode: 0755}}
+		for _, file := range append([]BuildFile{root}, files...) {
+			header := b.tarHeader(file.DataEntry, modTime)
+
+			switch file.Type {
+			case EntryRegular:
+				hash := md5.New()
+				err := writeContent(tarWriter, header, file.Data, file.Source, hash)
+				if err != nil {
+					return err
+				}
+				sums[file.Path] = hex.EncodeToString(hash.Sum(nil))
+				installedSize += (header.Size + 1023) / 1024
+				continue
+			case EntryDirectory, EntrySymlink:
+				installedSize++
+			case EntryHardlink:
+				target, err := cleanArchivePath(strings.TrimPrefix(file.LinkTarget, "/"))
+				if err != nil {
+					return err
+				}
+				header.Linkname = "./" + target
+			default:
+				return fmt.Errorf("go-apt/dpkg: unsupported entry type %s for %s", file.Type, file.Path)
+			}
+
+			if err := tarWriter.WriteHeader(header); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return archive, installedSize, sums, err
+}
+
+// buildControlArchive writes the compressed control archive to a temporary file
+func (b *Builder) buildControlArchive(installedSize int64, sums map[string]string, modTime time.Time) (*os.File, error) {
+	// The control paragraph of the builder is not modified
+	control := &DebPackage{}
+	for name, value := range b.Control.All() {
+		control.Set(name, value)
+	}
+	if !control.Has("Installed-Size") {
+		control.Set("Installed-Size", strconv.FormatInt(installedSize, 10))
+	}
+
+	var controlData bytes.Buffer
+	if err := NewParagraphWriter(&controlData).Write(&control.Paragraph); err != nil {
+		return nil, err
+	}
+	members := []ControlFile{{Name: "control", Mode: 0644, Data: controlData.Bytes()}}
+
+	hasMD5Sums := slices.ContainsFunc(b.ControlFiles, func(file ControlFile) bool { return file.Name == "md5sums" })
+	if !hasMD5Sums && len(sums) > 0 {
+		data, err := b.md5sums(sums)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, ControlFile{Name: "md5sums", Mode: 0644, Data: data})
+	}
+
+	for _, file := range b.ControlFiles {
+		if file.Name == "control" || strings.Contains(file.Name, "/") {
+			continue
+		}
+		members = append(members, file)
+	}
+
+	return b.writeArchive(func(tarWriter *tar.Writer) error {
+		if err := tarWriter.WriteHeader(b.tarHeader(DataEntry{Path: ".", Type: EntryDirectory, Mode: 0755}, modTime)); err != nil {
+			return err
+		}
+		for _, member := range members {
+			header := b.tarHeader(DataEntry{Path: member.Name, Mode: member.Mode}, modTime)
+			if err := writeContent(tarWriter, header, member.Data, member.Source, io.Discard); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// writeArchive writes a tar archive compressed with the compression of the builder to
+// a temporary file, which the caller removes with removeTempFile
+func (b *Builder) writeArchive(write func(tarWriter *tar.Writer) error) (*os.File, error) {
+	file, err := os.CreateTemp("", "go-dpkg-build-*")
+	if err != nil {
+		return nil, err
+	}
+
+	compressor, err := newCompressor(b.Compression, file)
+	if err == nil {
+		tarWriter := tar.NewWriter(compressor)
+		err = write(tarWriter)
+		if err == nil {
+			err = tarWriter.Close()
+		}
+		err = errors.Join(err, compressor.Close())
+	}
+	if err != nil {
+		removeTempFile(file)
+		return nil, err
+	}
+	return file, nil
+}
+
+// writeContent writes a regular file to the tar archive, from data or else from the
+// source file, copying its content to hash as well; header.Size is set to its size
+func writeContent(tarWriter *tar.Writer, header *tar.Header, data []byte, source string, hash io.Writer) error {
+	content := io.Reader(bytes.NewReader(data))
+	header.Size = int64(len(data))
+
+	if data == nil && source != "" {
+		file, err := os.Open(source)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		info, err := file.Stat()
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return fmt.Errorf("go-apt/dpkg: %s is not a regular file", source)
+		}
+		content, header.Size = file, info.Size()
+	}
+
+	if err := tarWriter.WriteHeader(header); err != nil {
+		return err
+	}
+	if _, err := io.CopyN(io.MultiWriter(tarWriter, hash), content, header.Size); err != nil {
+		if errors.Is(err, io.EOF) {
+			return fmt.Errorf("go-apt/dpkg: %s was truncated while building", source)
+		}
+		return err
+	}
+	return nil
+}
+
+// removeTempFile closes and removes a temporary file
+func removeTempFile(file *os.File) {
+	file.Close()
+	os.Remove(file.Name())
+}
+
+// md5sums returns the md5sums control member, without the conffiles like dh_md5sums
+func (b *Builder) md5sums(sums map[string]string) ([]byte, error) {
+	conffiles := make(map[string]bool)
+	for _, file := range b.ControlFiles {
+		if file.Name != "conffiles" {
+			continue
+		}
+		data := file.Data
+		if data == nil && file.Source != "" {
+			var err error
+			if data, err = os.ReadFile(file.Source); err != nil {
+				return nil, err
+			}
+		}
+		entries, err := ParseConffiles(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		for _, conffile := range entries {
+			conffiles[strings.TrimPrefix(conffile.Path, "/")] = true
+		}
+	}
+
+	names := make([]string, 0, len(sums))
+	for name := range sums {
+		if !conffiles[name] {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	var buf bytes.Buffer
+	for _, name := range names {
+		fmt.Fprintf(&buf, "%s  %s\n", sums[name], name)
+	}
+	return buf.Bytes(), nil
+}
+
+// tarHeader returns the tar header of an entry, with normalized ownership and a
+// modification time clamped to modTime when it is set
+func (b *Builder) tarHeader(entry DataEntry, modTime time.Time) *tar.Header {
+	name := "./" + entry.Path
+	if entry.Path == "." {
+		name = "./"
+	} else if entry.Type == EntryDirectory {
+		name += "/"
+	}
+
+	mode := int64(entry.Mode.Perm())
+	if entry.Mode&fs.ModeSetuid != 0 {
+		mode |= 04000
+	}
+	if entry.Mode&fs.ModeSetgid != 0 {
+		mode |= 02000
+	}
+	if entry.Mode&fs.ModeSticky != 0 {
+		mode |= 01000
+	}
+
+	header := &tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Mode:     mode,
+		Uname:    "root",
+		Gname:    "root",
+		ModTime:  entry.ModTime,
+		Format:   tar.FormatGNU,
+	}
+	switch entry.Type {
+	case EntryDirectory:
+		header.Typeflag = tar.TypeDir
+	case EntrySymlink:
+		header.Typeflag = tar.TypeSymlink
+		header.Linkname = entry.LinkTarget
+	case EntryHardlink:
+		header.Typeflag = tar.TypeLink
+	}
+	if b.PreserveOwnership {
+		header.Uid, header.Gid = entry.UID, entry.GID
+		header.Uname, header.Gname = entry.Owner, entry.Group
+	}
+	if !modTime.IsZero() && (header.ModTime.IsZero() || header.ModTime.After(modTime)) {
+		header.ModTime = modTime
+	}
+	if header.ModTime.IsZero() {
+		header.ModTime = time.Now()
+	}
+	header.ModTime = header.ModTime.Truncate(time.Second)
+
+	return header
+}
+
+// newCompressor returns a writer compressing to w; closing it does not close w
+func newCompressor(c Compression, w io.Writer) (io.WriteCloser, error) {
+	var writer io.WriteCloser
+	var err error
+
+	switch c {
+	case CompressionNone:
+		return nopWriteCloser{w}, nil
+	case CompressionGzip:
+		writer, err = gzip.NewWriterLevel(w, gzip.BestCompression)
+	case CompressionZstd:
+		writer, err = zstd.NewWriter(w, zstd.WithEncoderConcurrency(1))
+	case CompressionXz:
+		writer, err = xz.NewWriter(w)
+	default:
+		return nil, fmt.Errorf("go-apt/dpkg: unsupported compression %d", c)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("go-apt/dpkg: failed to create %s writer: %w", c, err)
+	}
+	return writer, nil
+}
+
+// nopWriteCloser adds a no-op Close method to a writer
+type nopWriteCloser struct {
+	io.Writer
+}
+
+// Close does nothing
+func (nopWriteCloser) Close() error {
+	return nil
+}
@@ -0,0 +1,11 @@
+//go:build !unix
+
+package dpkg
+
+import "io/fs"
+
+// ownerNames is empty, as the files have no Unix owner and group
+type ownerNames struct{}
+
+// setOwnership leaves the entry owned by root
+func (n *ownerNames) setOwnership(entry *DataEntry, info fs.FileInfo) {}
@@ -0,0 +1,225 @@
+package dpkg
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newTestBuilder creates a Builder for a small package
+func newTestBuilder() *Builder {
+	control := &DebPackage{}
+	control.Set("Package", "hello")
+	control.Set("Version", "1.0-1")
+	control.Set("Architecture", "all")
+	control.Set("Maintainer", "Jane Doe <jane@example.org>")
+	control.Set("Description", "greeting tool\n Prints a greeting.")
+
+	b := NewBuilder(control)
+	b.ControlFiles = []ControlFile{
+		{Name: "postinst", Mode: 0755, Data: []byte("#!/bin/sh\nexit 0\n")},
+		{Name: "conffiles", Mode: 0644, Data: []byte("/etc/hello.conf\n")},
+	}
+	b.Files = []BuildFile{
+		{DataEntry: DataEntry{Path: "usr/bin/hello", Type: EntryRegular, Mode: 0755}, Data: []byte("#!/bin/sh\necho hello\n")},
+		{DataEntry: DataEntry{Path: "/etc/hello.conf", Type: EntryRegular, Mode: 0644}, Data: bytes.Repeat([]byte("x"), 2000)},
+		{DataEntry: DataEntry{Path: "usr/bin/hi", Type: EntryHardlink, LinkTarget: "usr/bin/hello"}},
+		{DataEntry: DataEntry{Path: "usr/bin/greet", Type: EntrySymlink, Mode: 0777, LinkTarget: "hello"}},
+	}
+	b.ModTime = time.Date(2025, 2, 16, 1, 43, 0, 0, time.UTC)
+	return b
+}
+
+// TestBuild tests that built packages can be read back
+func TestBuild(t *testing.T) {
+	for _, compression := range []Compression{CompressionXz, CompressionGzip, CompressionZstd, CompressionNone} {
+		t.Run(compression.String(), func(t *testing.T) {
+			b := newTestBuilder()
+			b.Compression = compression
+
+			debFile := filepath.Join(t.TempDir(), "hello_1.0-1_all.deb")
+			var buf bytes.Buffer
+			if err := b.Build(&buf); err != nil {
+				t.Fatalf("Build failed: %v", err)
+			}
+			if err := os.WriteFile(debFile, buf.Bytes(), 0644); err != nil {
+				t.Fatalf("Failed to write package: %v", err)
+			}
+
+			d := Dpkg{}
+			if !d.IsDebFile(debFile) {
+				t.Fatalf("Built package is not a valid .deb file")
+			}
+
+			archive, err := d.Control(debFile)
+			if err != nil {
+				t.Fatalf("Control failed: %v", err)
+			}
+			// 1 KiB for the binary, 2 KiB for the conffile and 1 KiB for each of the
+			// symlink and the ./, ./etc, ./usr and ./usr/bin directories
+			if got := archive.Control.Get("Installed-Size"); got != "8" {
+				t.Errorf("Installed-Size = %q; want 8", got)
+			}
+			if _, ok := archive.MD5Sums["etc/hello.conf"]; ok || len(archive.MD5Sums) != 1 {
+				t.Errorf("MD5Sums = %v; want only usr/bin/hello", archive.MD5Sums)
+			}
+			if string(archive.Postinst) != "#!/bin/sh\nexit 0\n" || len(archive.Conffiles) != 1 {
+				t.Errorf("Control members were not written")
+			}
+
+			dir := t.TempDir()
+			if _, err := d.Extract(debFile, dir, ExtractOptions{}); err != nil {
+				t.Fatalf("Extract failed: %v", err)
+			}
+			info, err := os.Stat(filepath.Join(dir, "usr/bin/hi"))
+			if err != nil || info.Mode().Perm() != 0755 || !info.ModTime().Equal(b.ModTime) {
+				t.Errorf("usr/bin/hi = %v, %v", info, err)
+			}
+			if target, err := os.Readlink(filepath.Join(dir, "usr/bin/greet")); err != nil || target != "hello" {
+				t.Errorf("Readlink(usr/bin/greet) = %q, %v", target, err)
+			}
+
+			entries, err := d.Contents(debFile)
+			if err != nil {
+				t.Fatalf("Contents failed: %v", err)
+			}
+			for _, entry := range entries {
+				if entry.Owner != "root" || entry.Group != "root" || entry.UID != 0 || entry.GID != 0 {
+					t.Errorf("Entry %s is owned by %s/%s", entry.Path, entry.Owner, entry.Group)
+				}
+			}
+
+			// Builds with a fixed timestamp are reproducible
+			var again bytes.Buffer
+			if err := b.Build(&again); err != nil {
+				t.Fatalf("Build failed: %v", err)
+			}
+			if !bytes.Equal(buf.Bytes(), again.Bytes()) {
+				t.Errorf("Builds are not reproducible")
+			}
+		})
+	}
+}
+
+// TestBuildSourceDateEpoch tests that SOURCE_DATE_EPOCH clamps the modification times
+func TestBuildSourceDateEpoch(t *testing.T) {
+	t.Setenv("SOURCE_DATE_EPOCH", "1700000000")
+
+	b := newTestBuilder()
+	b.ModTime = time.Time{}
+	b.Files[0].ModTime = time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	debFile := filepath.Join(t.TempDir(), "hello.deb")
+	file, err := os.Create(debFile)
+	if err != nil {
+		t.Fatalf("Failed to create package: %v", err)
+	}
+	if err := b.Build(file); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	file.Close()
+
+	d := Dpkg{}
+	entries, err := d.Contents(debFile)
+	if err != nil {
+		t.Fatalf("Contents failed: %v", err)
+	}
+	for _, entry := range entries {
+		if entry.ModTime.Unix() != 1700000000 {
+			t.Errorf("Entry %s has time %v; want 1700000000", entry.Path, entry.ModTime.Unix())
+		}
+	}
+}
+
+// TestNewBuilderFromDir tests building a package from a directory tree
+func TestNewBuilderFromDir(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"DEBIAN/control":      "Package: hello\nVersion: 1.0-1\nArchitecture: all\nInstalled-Size: 42\n",
+		"DEBIAN/postinst":     "#!/bin/sh\n",
+		"usr/bin/hello":       "#!/bin/sh\necho hello\n",
+		"usr/share/doc/hello": "",
+	}
+	for name, content := range files {
+		filePath := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	if err := os.Chmod(filepath.Join(root, "usr/bin/hello"), 0755|fs.ModeSetuid); err != nil {
+		t.Fatalf("Failed to chmod: %v", err)
+	}
+	if err := os.Symlink("hello", filepath.Join(root, "usr/bin/hi")); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+
+	b, err := NewBuilderFromDir(root)
+	if err != nil {
+		t.Fatalf("NewBuilderFromDir failed: %v", err)
+	}
+	if len(b.ControlFiles) != 1 || b.ControlFiles[0].Name != "postinst" {
+		t.Errorf("ControlFiles = %+v; want postinst", b.ControlFiles)
+	}
+
+	debFile := filepath.Join(t.TempDir(), "hello.deb")
+	file, err := os.Create(debFile)
+	if err != nil {
+		t.Fatalf("Failed to create package: %v", err)
+	}
+	if err := b.Build(file); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	file.Close()
+
+	d := Dpkg{}
+	pkg, err := d.Info(debFile)
+	if err != nil {
+		t.Fatalf("Info failed: %v", err)
+	}
+	if pkg.Get("Installed-Size") != "42" {
+		t.Errorf("Installed-Size = %q; want the value of the control file", pkg.Get("Installed-Size"))
+	}
+
+	entries, err := d.Contents(debFile)
+	if err != nil {
+		t.Fatalf("Contents failed: %v", err)
+	}
+	var paths []string
+	for _, entry := range entries {
+		paths = append(paths, entry.String()[:10]+" "+entry.Path)
+	}
+	want := []string{
+		"drwxr-xr-x ./",
+		"drwxr-xr-x ./usr/",
+		"drwxr-xr-x ./usr/bin/",
+		"-rwsr-xr-x ./usr/bin/hello",
+		"lrwxrwxrwx ./usr/bin/hi",
+		"drwxr-xr-x ./usr/share/",
+		"drwxr-xr-x ./usr/share/doc/",
+		"-rw-r--r-- ./usr/share/doc/hello",
+	}
+	if len(paths) != len(want) {
+		t.Fatalf("Contents() = %v; want %v", paths, want)
+	}
+	for i := range want {
+		if paths[i] != want[i] {
+			t.Errorf("Entry %d = %q; want %q", i, paths[i], want[i])
+		}
+	}
+}
+
+// TestBuildMissingFields tests that the mandatory control fields are checked
+func TestBuildMissingFields(t *testing.T) {
+	b := newTestBuilder()
+	b.Control.Delete("Version")
+
+	if err := b.Build(&bytes.Buffer{}); err == nil {
+		t.Errorf("Expected an error for a missing Version field")
+	}
+}
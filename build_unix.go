@@ -0,0 +1,45 @@
+//go:build unix
+
+package dpkg
+
+import (
+	"io/fs"
+	"os/user"
+	"strconv"
+	"syscall"
+)
+
+// ownerNames caches the names of the owners and groups of the files of a directory tree
+type ownerNames struct {
+	users  map[int]string
+	groups map[int]string
+}
+
+// setOwnership sets the owner and group of the entry from the file information; the
+// names are left empty for ids without a user or group
+func (n *ownerNames) setOwnership(entry *DataEntry, info fs.FileInfo) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return
+	}
+	entry.UID, entry.GID = int(stat.Uid), int(stat.Gid)
+
+	if n.users == nil {
+		n.users, n.groups = make(map[int]string), make(map[int]string)
+	}
+	owner, ok := n.users[entry.UID]
+	if !ok {
+		if u, err := user.LookupId(strconv.Itoa(entry.UID)); err == nil {
+			owner = u.Username
+		}
+		n.users[entry.UID] = owner
+	}
+	group, ok := n.groups[entry.GID]
+	if !ok {
+		if g, err := user.LookupGroupId(strconv.Itoa(entry.GID)); err == nil {
+			group = g.Name
+		}
+		n.groups[entry.GID] = group
+	}
+	entry.Owner, entry.Group = owner, group
+}
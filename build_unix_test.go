@@ -0,0 +1,65 @@
+//go:build unix
+
+package dpkg
+
+import (
+	"bytes"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+// TestNewBuilderFromDirOwnership tests that the ownership of the directory tree is kept
+// with PreserveOwnership
+func TestNewBuilderFromDirOwnership(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "DEBIAN"), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "DEBIAN/control"), []byte("Package: hello\nVersion: 1.0-1\nArchitecture: all\n"), 0644); err != nil {
+		t.Fatalf("Failed to write control file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "hello"), []byte("hello\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	b, err := NewBuilderFromDir(root)
+	if err != nil {
+		t.Fatalf("NewBuilderFromDir failed: %v", err)
+	}
+	b.PreserveOwnership = true
+
+	var buf bytes.Buffer
+	if err := b.Build(&buf); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	a, err := OpenDeb(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("OpenDeb failed: %v", err)
+	}
+	entries, err := a.Contents()
+	if err != nil {
+		t.Fatalf("Contents failed: %v", err)
+	}
+
+	owner, group := "", ""
+	if u, err := user.LookupId(strconv.Itoa(os.Getuid())); err == nil {
+		owner = u.Username
+	}
+	if g, err := user.LookupGroupId(strconv.Itoa(os.Getgid())); err == nil {
+		group = g.Name
+	}
+	for _, entry := range entries {
+		if entry.Path != "./hello" {
+			continue
+		}
+		if entry.UID != os.Getuid() || entry.GID != os.Getgid() || entry.Owner != owner || entry.Group != group {
+			t.Errorf("Ownership = %d/%d %s/%s; want %d/%d %s/%s", entry.UID, entry.GID, entry.Owner, entry.Group,
+				os.Getuid(), os.Getgid(), owner, group)
+		}
+		return
+	}
+	t.Errorf("./hello not found in %v", entries)
+}
@@ -4,6 +4,7 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/go-apt/dpkg"
 )
@@ -12,6 +13,18 @@ func main() {
 	// Define flags
 	infoFlag := flag.Bool("I", false, "show information about a package")
 	listFlag := flag.Bool("l", false, "list packages matching given pattern")
+	contentsFlag := flag.Bool("c", false, "list the contents of a package")
+	extractFlag := flag.Bool("x", false, "extract the files of a package into a directory")
+	controlFlag := flag.Bool("e", false, "extract the control information of a package into a directory (default DEBIAN)")
+	buildFlag := flag.Bool("b", false, "build a package from a directory tree")
+	compressionFlag := flag.String("Z", "xz", "compression used by -b: xz, gzip, zstd or none")
+	validateFlag := flag.Bool("validate", false, "check the structure of a package")
+	filesFlag := flag.Bool("L", false, "list the files installed by a package")
+	searchFlag := flag.Bool("S", false, "search the installed packages owning a path or glob pattern")
+	verifyFlag := flag.Bool("V", false, "verify the installed files of the given packages, or of all packages")
+	rootFlag := flag.String("root", "", "directory where the packages are installed, e.g. a mounted image")
+	adminDirFlag := flag.String("admindir", "", "directory of the dpkg database (default /var/lib/dpkg below -root)")
+	databaseFlag := flag.String("database", "status", "file of the dpkg database listed by -l: status, status-old or available")
 	helpFlag := flag.Bool("?", false, "show this help message")
 
 	// Parse flags
@@ -31,15 +44,25 @@ func main() {
 
 	// Create a new instance of the Dpkg struct
 	d := dpkg.NewDpkg()
+	d.Root = *rootFlag
+	d.AdminDir = *adminDirFlag
+
+	database, err := dpkg.ParseDatabase(*databaseFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	d.Database = database
 
 	// Check if info flag is activated
 	if *infoFlag {
 		// Check if a .deb file is provided as an argument
-		if len(os.Args) < 3 {
+		args := flag.Args()
+		if len(args) < 1 {
 			printUsage()
 			os.Exit(1)
 		}
-		debFile := os.Args[2]
+		debFile := args[0]
 
 		// Validate if the file is a .deb package
 		if !d.IsDebFile(debFile) {
@@ -58,9 +81,184 @@ func main() {
 		fmt.Printf("Package from .deb file: %s\n", pkg)
 	}
 
+	// Check if contents flag is activated
+	if *contentsFlag {
+		// Check if a .deb file is provided as an argument
+		args := flag.Args()
+		if len(args) < 1 {
+			printUsage()
+			os.Exit(1)
+		}
+		debFile := args[0]
+
+		// Validate if the file is a .deb package
+		if !d.IsDebFile(debFile) {
+			fmt.Fprintf(os.Stderr, "Error: %s is not a valid .deb file\n", debFile)
+			os.Exit(1)
+		}
+
+		// Read the entries of the data archive
+		entries, err := d.Contents(debFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Print the entries like dpkg -c
+		for _, entry := range entries {
+			fmt.Println(entry)
+		}
+	}
+
+	// Check if extract flag is activated
+	if *extractFlag {
+		// Check if a .deb file and a target directory are provided as arguments
+		args := flag.Args()
+		if len(args) < 2 {
+			printUsage()
+			os.Exit(1)
+		}
+		debFile, targetDir := args[0], args[1]
+
+		// Validate if the file is a .deb package
+		if !d.IsDebFile(debFile) {
+			fmt.Fprintf(os.Stderr, "Error: %s is not a valid .deb file\n", debFile)
+			os.Exit(1)
+		}
+
+		// Extract the data archive, keeping the ownership when running as root
+		_, err := d.Extract(debFile, targetDir, dpkg.ExtractOptions{PreserveOwnership: true})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	// Check if control flag is activated
+	if *controlFlag {
+		// Check if a .deb file is provided as an argument
+		args := flag.Args()
+		if len(args) < 1 {
+			printUsage()
+			os.Exit(1)
+		}
+		debFile, targetDir := args[0], "DEBIAN"
+		if len(args) > 1 {
+			targetDir = args[1]
+		}
+
+		// Validate if the file is a .deb package
+		if !d.IsDebFile(debFile) {
+			fmt.Fprintf(os.Stderr, "Error: %s is not a valid .deb file\n", debFile)
+			os.Exit(1)
+		}
+
+		// Read the control archive and write its members
+		archive, err := d.Control(debFile)
+		if err == nil {
+			err = archive.ExtractTo(targetDir)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	// Check if build flag is activated
+	if *buildFlag {
+		// Check if a directory and a .deb file are provided as arguments
+		args := flag.Args()
+		if len(args) < 2 {
+			printUsage()
+			os.Exit(1)
+		}
+
+		if err := buildPackage(args[0], args[1], *compressionFlag); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	// Check if validate flag is activated
+	if *validateFlag {
+		// Check if a .deb file is provided as an argument
+		args := flag.Args()
+		if len(args) < 1 {
+			printUsage()
+			os.Exit(1)
+		}
+
+		findings, err := d.ValidateDeb(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Print the findings, and fail if any of them is an error
+		for _, finding := range findings {
+			fmt.Println(finding)
+		}
+		if dpkg.HasErrors(findings) {
+			os.Exit(1)
+		}
+	}
+
+	// Check if files flag is activated
+	if *filesFlag {
+		// Check if a package name is provided as an argument
+		args := flag.Args()
+		if len(args) < 1 {
+			printUsage()
+			os.Exit(1)
+		}
+
+		files, err := d.Files(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		for _, file := range files {
+			fmt.Println(file)
+		}
+	}
+
+	// Check if search flag is activated
+	if *searchFlag {
+		// Check if a pattern is provided as an argument
+		args := flag.Args()
+		if len(args) < 1 {
+			printUsage()
+			os.Exit(1)
+		}
+
+		owners, err := d.Search(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if len(owners) == 0 {
+			fmt.Fprintf(os.Stderr, "Error: no path found matching pattern %s\n", args[0])
+			os.Exit(1)
+		}
+
+		// Print the owners like dpkg -S
+		for _, owner := range owners {
+			fmt.Printf("%s: %s\n", strings.Join(owner.Packages, ", "), owner.Path)
+		}
+	}
+
+	// Check if verify flag is activated
+	if *verifyFlag {
+		if err := verifyPackages(d, flag.Args()); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
 	// Check if list flag is activated
 	if *listFlag {
-		if len(os.Args) == 2 {
+		args := flag.Args()
+		if len(args) == 0 {
 			// Read the contents of the /var/lib/dpkg/status file
 			packages, err := d.List()
 			if err != nil {
@@ -70,10 +268,10 @@ func main() {
 
 			// Print package name from installed packages
 			for _, p := range packages {
-				fmt.Printf("Package from dpkg status file: %s\n", p)
+				fmt.Printf("Package from dpkg status file: %s\n", &p)
 			}
-		} else if len(os.Args) == 3 {
-			patternName := os.Args[2]
+		} else {
+			patternName := args[0]
 
 			// List packages matching the given pattern
 			filteredPackages, err := d.ListGrep(patternName)
@@ -84,12 +282,74 @@ func main() {
 
 			// Print package name from installed packages
 			for _, p := range filteredPackages {
-				fmt.Printf("Packages from Grep search: %s\n", p)
+				fmt.Printf("Packages from Grep search: %s\n", &p)
 			}
 		}
 	}
 }
 
+// buildPackage builds the package of the directory tree into debFile
+func buildPackage(root, debFile, compression string) error {
+	builder, err := dpkg.NewBuilderFromDir(root)
+	if err != nil {
+		return err
+	}
+	if builder.Compression, err = dpkg.ParseCompression(compression); err != nil {
+		return err
+	}
+
+	file, err := os.Create(debFile)
+	if err != nil {
+		return err
+	}
+	if err := builder.Build(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// verifyPackages prints the problems of the installed files of the packages like
+// dpkg --verify, and fails if any was found. Without names, every installed package is
+// verified while reading the database once.
+func verifyPackages(d *dpkg.Dpkg, pkgNames []string) error {
+	failed := false
+	verify := func(report *dpkg.VerifyReport) {
+		for _, file := range report.Problems() {
+			fmt.Println(file)
+			failed = true
+		}
+	}
+
+	if len(pkgNames) == 0 {
+		for pkg, err := range d.Packages() {
+			if err != nil {
+				return err
+			}
+			if status, err := pkg.Status(); err != nil || status.State == dpkg.StateNotInstalled {
+				continue
+			}
+			report, err := d.VerifyPackage(pkg)
+			if err != nil {
+				return err
+			}
+			verify(report)
+		}
+	}
+	for _, pkgName := range pkgNames {
+		report, err := d.Verify(pkgName)
+		if err != nil {
+			return err
+		}
+		verify(report)
+	}
+
+	if failed {
+		return fmt.Errorf("verification failed")
+	}
+	return nil
+}
+
 // printUsage prints the usage information
 func printUsage() {
 	fmt.Println("Usage: go-dpkg [<option>...]")
@@ -3,12 +3,25 @@ package dpkg
 import "errors"
 
 const (
-	DPKG_DATABASE = "/var/lib/dpkg/status"
+	DPKG_ADMINDIR = "/var/lib/dpkg"
+	DPKG_DATABASE = DPKG_ADMINDIR + "/status"
 )
 
 var (
 	ErrDebHeader           = errors.New("go-apt/dpkg: invalid debian package (ar magic header not matched)")
 	ErrNoControlFile       = errors.New("go-apt/dpkg: failed to find control.tar file")
+	ErrNoDataFile          = errors.New("go-apt/dpkg: failed to find data.tar file")
 	ErrNoDpkgStatusFile    = errors.New("go-apt/dpkg: failed to read " + DPKG_DATABASE + " file")
 	ErrNoFilenameAvailable = errors.New("go-apt/dpkg: no Filename available for this package")
+	ErrInvalidVersion      = errors.New("go-apt/dpkg: invalid version")
+	ErrInvalidConstraint   = errors.New("go-apt/dpkg: invalid version constraint")
+	ErrInvalidRelationship = errors.New("go-apt/dpkg: invalid relationship")
+	ErrInvalidField        = errors.New("go-apt/dpkg: invalid field")
+	ErrNoParagraph         = errors.New("go-apt/dpkg: no paragraph found")
+	ErrUnsafePath          = errors.New("go-apt/dpkg: unsafe path in data archive")
+	ErrNoMD5Sums           = errors.New("go-apt/dpkg: failed to find md5sums file")
+	ErrPackageNotInstalled = errors.New("go-apt/dpkg: package is not installed")
+	ErrAmbiguousPackage    = errors.New("go-apt/dpkg: ambiguous package name")
+	ErrInvalidPattern      = errors.New("go-apt/dpkg: invalid pattern")
+	ErrLocked              = errors.New("go-apt/dpkg: dpkg database is locked by another process")
 )
@@ -0,0 +1,117 @@
+package dpkg
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Relation represents a version relationship operator used in dependency fields
+// https://www.debian.org/doc/debian-policy/ch-relationships.html#syntax-of-relationship-fields
+type Relation int
+
+const (
+	RelationLT Relation = iota + 1 // << strictly earlier
+	RelationLE                     // <= earlier or equal
+	RelationEQ                     // =  exactly equal
+	RelationGE                     // >= later or equal
+	RelationGT                     // >> strictly later
+)
+
+// relationOperators maps the operators to their relation, longest operators
+// first so that "<<" is not mistaken for the deprecated "<"
+var relationOperators = []struct {
+	op       string
+	relation Relation
+}{
+	{"<<", RelationLT},
+	{"<=", RelationLE},
+	{">=", RelationGE},
+	{">>", RelationGT},
+	{"=", RelationEQ},
+	// Deprecated forms, which dpkg interprets as <= and >=
+	{"<", RelationLE},
+	{">", RelationGE},
+}
+
+// String returns the canonical operator of the relation
+func (r Relation) String() string {
+	switch r {
+	case RelationLT:
+		return "<<"
+	case RelationLE:
+		return "<="
+	case RelationEQ:
+		return "="
+	case RelationGE:
+		return ">="
+	case RelationGT:
+		return ">>"
+	}
+	return ""
+}
+
+// VersionConstraint represents a version restriction such as "(>= 1.2-3)"
+type VersionConstraint struct {
+	Relation Relation
+	Version  Version
+}
+
+// ParseVersionConstraint parses a version constraint, with or without the
+// surrounding parentheses, e.g. "(>= 1.2-3)", "<< 2.0" or "(=1:4.5)"
+func ParseVersionConstraint(s string) (VersionConstraint, error) {
+	constraint := strings.TrimSpace(s)
+
+	// Remove the surrounding parentheses
+	if strings.HasPrefix(constraint, "(") {
+		if !strings.HasSuffix(constraint, ")") {
+			return VersionConstraint{}, fmt.Errorf("%w %q: missing closing parenthesis", ErrInvalidConstraint, s)
+		}
+		constraint = strings.TrimSpace(constraint[1 : len(constraint)-1])
+	} else if strings.HasSuffix(constraint, ")") {
+		return VersionConstraint{}, fmt.Errorf("%w %q: missing opening parenthesis", ErrInvalidConstraint, s)
+	}
+
+	var vc VersionConstraint
+	for _, ro := range relationOperators {
+		if strings.HasPrefix(constraint, ro.op) {
+			vc.Relation = ro.relation
+			constraint = constraint[len(ro.op):]
+			break
+		}
+	}
+	if vc.Relation == 0 {
+		return VersionConstraint{}, fmt.Errorf("%w %q: unknown relation operator", ErrInvalidConstraint, s)
+	}
+
+	version, err := ParseVersion(constraint)
+	if err != nil {
+		return VersionConstraint{}, fmt.Errorf("%w %q: %w", ErrInvalidConstraint, s, err)
+	}
+	vc.Version = version
+
+	return vc, nil
+}
+
+// Satisfies checks if the given version satisfies the constraint
+func (vc VersionConstraint) Satisfies(v Version) bool {
+	cmp := v.Compare(vc.Version)
+
+	switch vc.Relation {
+	case RelationLT:
+		return cmp < 0
+	case RelationLE:
+		return cmp <= 0
+	case RelationEQ:
+		return cmp == 0
+	case RelationGE:
+		return cmp >= 0
+	case RelationGT:
+		return cmp > 0
+	}
+	return false
+}
+
+// String returns the constraint in its canonical form, e.g. "(>= 1.2-3)"
+func (vc VersionConstraint) String() string {
+	return "(" + vc.Relation.String() + " " + vc.Version.String() + ")"
+}
@@ -0,0 +1,94 @@
+package dpkg
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestParseVersionConstraint tests the ParseVersionConstraint function
+func TestParseVersionConstraint(t *testing.T) {
+	tests := []struct {
+		constraint   string
+		wantRelation Relation
+		wantVersion  string
+		wantString   string
+		wantErr      bool
+	}{
+		{"(>= 1.2-3)", RelationGE, "1.2-3", "(>= 1.2-3)", false},
+		{"(<< 2.0)", RelationLT, "2.0", "(<< 2.0)", false},
+		{"(= 1:4.5)", RelationEQ, "1:4.5", "(= 1:4.5)", false},
+		{"(>> 0.1)", RelationGT, "0.1", "(>> 0.1)", false},
+		{"(<= 3~rc1)", RelationLE, "3~rc1", "(<= 3~rc1)", false},
+		{"(< 1.0)", RelationLE, "1.0", "(<= 1.0)", false},
+		{"(> 1.0)", RelationGE, "1.0", "(>= 1.0)", false},
+		{"(>=1.0)", RelationGE, "1.0", "(>= 1.0)", false},
+		{">= 1.0", RelationGE, "1.0", "(>= 1.0)", false},
+		{"( =  2:9.1.1113-1 )", RelationEQ, "2:9.1.1113-1", "(= 2:9.1.1113-1)", false},
+		{"(>= 1.0", 0, "", "", true},
+		{">= 1.0)", 0, "", "", true},
+		{"(~ 1.0)", 0, "", "", true},
+		{"(>= )", 0, "", "", true},
+		{"(>= a.b)", 0, "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.constraint, func(t *testing.T) {
+			vc, err := ParseVersionConstraint(tt.constraint)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidConstraint) {
+					t.Errorf("ParseVersionConstraint(%q) error = %v; want ErrInvalidConstraint", tt.constraint, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseVersionConstraint(%q) unexpected error: %v", tt.constraint, err)
+			}
+			if vc.Relation != tt.wantRelation || vc.Version.String() != tt.wantVersion {
+				t.Errorf("ParseVersionConstraint(%q) = (%v, %q); want (%v, %q)",
+					tt.constraint, vc.Relation, vc.Version, tt.wantRelation, tt.wantVersion)
+			}
+			if got := vc.String(); got != tt.wantString {
+				t.Errorf("ParseVersionConstraint(%q).String() = %q; want %q", tt.constraint, got, tt.wantString)
+			}
+		})
+	}
+}
+
+// TestVersionConstraintSatisfies tests the Satisfies method of VersionConstraint
+func TestVersionConstraintSatisfies(t *testing.T) {
+	tests := []struct {
+		constraint string
+		version    string
+		want       bool
+	}{
+		{"(>= 1.2-3)", "1.2-3", true},
+		{"(>= 1.2-3)", "1.2-4", true},
+		{"(>= 1.2-3)", "1.2-2", false},
+		{"(<< 2.0)", "2.0~rc1", true},
+		{"(<< 2.0)", "2.0", false},
+		{"(= 1:4.5)", "1:4.5", true},
+		{"(= 1:4.5)", "4.5", false},
+		{"(>> 1.0)", "1.0", false},
+		{"(>> 1.0)", "1.0-1", true},
+		{"(<= 1.0)", "1.0", true},
+		{"(<= 1.0)", "1.0+b1", false},
+		{"(< 1.0)", "1.0", true},
+		{"(> 1.0)", "1.0", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.constraint+"_"+tt.version, func(t *testing.T) {
+			vc, err := ParseVersionConstraint(tt.constraint)
+			if err != nil {
+				t.Fatalf("ParseVersionConstraint(%q) unexpected error: %v", tt.constraint, err)
+			}
+			v, err := ParseVersion(tt.version)
+			if err != nil {
+				t.Fatalf("ParseVersion(%q) unexpected error: %v", tt.version, err)
+			}
+			if got := vc.Satisfies(v); got != tt.want {
+				t.Errorf("%s.Satisfies(%q) = %v; want %v", tt.constraint, tt.version, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package dpkg
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"io/fs"
+	"strconv"
+	"time"
+)
+
+// EntryType represents the type of an entry of a package data archive
+type EntryType int
+
+const (
+	EntryRegular EntryType = iota
+	EntryDirectory
+	EntrySymlink
+	EntryHardlink
+	EntryCharDevice
+	EntryBlockDevice
+	EntryFIFO
+)
+
+// String returns the character used by tar and dpkg -c for the entry type
+func (t EntryType) String() string {
+	switch t {
+	case EntryDirectory:
+		return "d"
+	case EntrySymlink:
+		return "l"
+	case EntryHardlink:
+		return "h"
+	case EntryCharDevice:
+		return "c"
+	case EntryBlockDevice:
+		return "b"
+	case EntryFIFO:
+		return "p"
+	}
+	return "-"
+}
+
+// DataEntry represents an entry of the data archive of a package
+type DataEntry struct {
+	Path       string
+	Type       EntryType
+	Mode       fs.FileMode // Permission bits, including setuid, setgid and sticky
+	Owner      string
+	Group      string
+	UID        int
+	GID        int
+	Size       int64
+	ModTime    time.Time
+	LinkTarget string // Target of symlinks and hardlinks
+}
+
+// Contents lists the entries of the data archive of a Debian package, like dpkg -c
+func (d *Dpkg) Contents(debFile string) ([]DataEntry, error) {
+	var entries []DataEntry
+
+	err := openDebFile(debFile, func(a *DebArchive) error {
+		var err error
+		entries, err = a.Contents()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// walkDataArchive calls fn for every entry of the uncompressed data archive
+func walkDataArchive(uncompressedReader io.Reader, fn func(header *tar.Header, r io.Reader) error) error {
+	tarReader := tar.NewReader(uncompressedReader)
+	for {
+		tarHeader, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("go-apt/dpkg: failed to read tar header: %w", err)
+		}
+
+		if err := fn(tarHeader, tarReader); err != nil {
+			return err
+		}
+	}
+}
+
+// newDataEntry creates a DataEntry from a tar header
+func newDataEntry(header *tar.Header) (DataEntry, error) {
+	entry := DataEntry{
+		Path:    header.Name,
+		Mode:    header.FileInfo().Mode() & (fs.ModePerm | fs.ModeSetuid | fs.ModeSetgid | fs.ModeSticky),
+		Owner:   header.Uname,
+		Group:   header.Gname,
+		UID:     header.Uid,
+		GID:     header.Gid,
+		Size:    header.Size,
+		ModTime: header.ModTime,
+	}
+
+	switch header.Typeflag {
+	case tar.TypeReg, tar.TypeRegA:
+		entry.Type = EntryRegular
+	case tar.TypeDir:
+		entry.Type = EntryDirectory
+	case tar.TypeSymlink:
+		entry.Type = EntrySymlink
+		entry.LinkTarget = header.Linkname
+	case tar.TypeLink:
+		entry.Type = EntryHardlink
+		entry.LinkTarget = header.Linkname
+	case tar.TypeChar:
+		entry.Type = EntryCharDevice
+	case tar.TypeBlock:
+		entry.Type = EntryBlockDevice
+	case tar.TypeFifo:
+		entry.Type = EntryFIFO
+	default:
+		return entry, fmt.Errorf("go-apt/dpkg: unsupported tar entry type %q for %s", header.Typeflag, header.Name)
+	}
+
+	return entry, nil
+}
+
+// String returns the entry in the format of dpkg -c, e.g.
+// "-rw-r--r-- root/root       662 2025-02-16 01:43 ./etc/vim/vimrc.tiny"
+func (e DataEntry) String() string {
+	owner := e.Owner
+	if owner == "" {
+		owner = strconv.Itoa(e.UID)
+	}
+	group := e.Group
+	if group == "" {
+		group = strconv.Itoa(e.GID)
+	}
+
+	// Owner, group and size share a column of 19 characters, like GNU tar
+	ownerGroup := owner + "/" + group
+	size := strconv.FormatInt(e.Size, 10)
+	width := max(18-len(ownerGroup), len(size))
+
+	line := fmt.Sprintf("%s %s %*s %s %s", e.modeString(), ownerGroup, width, size, e.ModTime.Format("2006-01-02 15:04"), e.Path)
+
+	switch e.Type {
+	case EntrySymlink:
+		line += " -> " + e.LinkTarget
+	case EntryHardlink:
+		line += " link to " + e.LinkTarget
+	}
+	return line
+}
+
+// modeString returns the permissions of the entry in the format of ls -l
+func (e DataEntry) modeString() string {
+	const rwx = "rwxrwxrwx"
+
+	mode := []byte(e.Type.String() + "---------")
+	for i := 0; i < 9; i++ {
+		if e.Mode&(1<<uint(8-i)) != 0 {
+			mode[i+1] = rwx[i]
+		}
+	}
+
+	// Special bits replace the execute permissions
+	special := []struct {
+		bit   fs.FileMode
+		index int
+		char  byte
+	}{
+		{fs.ModeSetuid, 3, 's'},
+		{fs.ModeSetgid, 6, 's'},
+		{fs.ModeSticky, 9, 't'},
+	}
+	for _, s := range special {
+		if e.Mode&s.bit != 0 {
+			if mode[s.index] == 'x' {
+				mode[s.index] = s.char
+			} else {
+				mode[s.index] = s.char - 'a' + 'A'
+			}
+		}
+	}
+
+	return string(mode)
+}
@@ -0,0 +1,113 @@
+package dpkg
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+	"time"
+)
+
+// TestContents tests the Contents method of Dpkg
+func TestContents(t *testing.T) {
+	tests := []struct {
+		filePath string
+		path     string
+		want     DataEntry
+	}{
+		{
+			"testdata/debs/vim-tiny_9.1.1113-1_amd64.deb",
+			"./etc/vim/vimrc.tiny",
+			DataEntry{Type: EntryRegular, Mode: 0644, Owner: "root", Group: "root", Size: 662},
+		},
+		{
+			"testdata/debs/vim-tiny_9.1.1113-1_amd64.deb",
+			"./usr/bin/vim.tiny",
+			DataEntry{Type: EntryRegular, Mode: 0755, Owner: "root", Group: "root", Size: 1748552},
+		},
+		{
+			"testdata/debs/vim-tiny_7.0-122+1etch5_amd64.deb",
+			"./usr/share/doc/vim-tiny",
+			DataEntry{Type: EntrySymlink, Mode: 0777, Owner: "root", Group: "root", LinkTarget: "vim-common"},
+		},
+	}
+
+	for _, test := range tests {
+		d := Dpkg{}
+		entries, err := d.Contents(test.filePath)
+		if err != nil {
+			t.Fatalf("Failed to list contents of %s: %v", test.filePath, err)
+		}
+
+		var found bool
+		for _, entry := range entries {
+			if entry.Path != test.path {
+				continue
+			}
+			found = true
+			if entry.Type != test.want.Type || entry.Mode != test.want.Mode || entry.Owner != test.want.Owner ||
+				entry.Group != test.want.Group || entry.Size != test.want.Size || entry.LinkTarget != test.want.LinkTarget {
+				t.Errorf("Entry %s of %s = %+v; want %+v", test.path, test.filePath, entry, test.want)
+			}
+		}
+		if !found {
+			t.Errorf("Entry %s not found in %s", test.path, test.filePath)
+		}
+	}
+}
+
+// TestContentsNoDataFile tests that Contents reports a missing data archive
+func TestContentsNoDataFile(t *testing.T) {
+	d := Dpkg{}
+	if _, err := d.Contents("testdata/compressed/control.tar.gz"); err == nil {
+		t.Errorf("Expected an error for a file which is not a .deb")
+	}
+
+	if _, err := d.Contents("testdata/debs/missing.deb"); err == nil || errors.Is(err, ErrNoDataFile) {
+		t.Errorf("Expected an open error for a missing file, got %v", err)
+	}
+}
+
+// TestDataEntryString tests the dpkg -c format of DataEntry
+func TestDataEntryString(t *testing.T) {
+	modTime := time.Date(2025, 2, 16, 1, 43, 0, 0, time.UTC)
+
+	tests := []struct {
+		entry DataEntry
+		want  string
+	}{
+		{
+			DataEntry{Path: "./etc/vim/vimrc.tiny", Type: EntryRegular, Mode: 0644, Owner: "root", Group: "root", Size: 662, ModTime: modTime},
+			"-rw-r--r-- root/root       662 2025-02-16 01:43 ./etc/vim/vimrc.tiny",
+		},
+		{
+			DataEntry{Path: "./usr/", Type: EntryDirectory, Mode: 0755, Owner: "root", Group: "root", ModTime: modTime},
+			"drwxr-xr-x root/root         0 2025-02-16 01:43 ./usr/",
+		},
+		{
+			DataEntry{Path: "./usr/bin/su", Type: EntryRegular, Mode: 0755 | fs.ModeSetuid, Owner: "root", Group: "root", Size: 80376, ModTime: modTime},
+			"-rwsr-xr-x root/root     80376 2025-02-16 01:43 ./usr/bin/su",
+		},
+		{
+			DataEntry{Path: "./var/mail/", Type: EntryDirectory, Mode: 0664 | fs.ModeSetgid, UID: 0, GID: 8, ModTime: modTime},
+			"drw-rwSr-- 0/8               0 2025-02-16 01:43 ./var/mail/",
+		},
+		{
+			DataEntry{Path: "./tmp/", Type: EntryDirectory, Mode: 0777 | fs.ModeSticky, Owner: "root", Group: "root", ModTime: modTime},
+			"drwxrwxrwt root/root         0 2025-02-16 01:43 ./tmp/",
+		},
+		{
+			DataEntry{Path: "./usr/bin/vi", Type: EntrySymlink, Mode: 0777, Owner: "root", Group: "root", ModTime: modTime, LinkTarget: "vim.tiny"},
+			"lrwxrwxrwx root/root         0 2025-02-16 01:43 ./usr/bin/vi -> vim.tiny",
+		},
+		{
+			DataEntry{Path: "./usr/bin/ex", Type: EntryHardlink, Mode: 0755, Owner: "root", Group: "root", ModTime: modTime, LinkTarget: "./usr/bin/vim.tiny"},
+			"hrwxr-xr-x root/root         0 2025-02-16 01:43 ./usr/bin/ex link to ./usr/bin/vim.tiny",
+		},
+	}
+
+	for _, test := range tests {
+		if got := test.entry.String(); got != test.want {
+			t.Errorf("String() = %q; want %q", got, test.want)
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package dpkg
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ControlFile represents a member of the control archive of a package
+type ControlFile struct {
+	Name   string
+	Mode   fs.FileMode
+	Data   []byte
+	Source string // Path of the file to read the content from when building, when Data is nil
+}
+
+// Conffile represents an entry of the conffiles control member, or of the Conffiles
+// field of the dpkg database
+type Conffile struct {
+	Path            string
+	RemoveOnUpgrade bool   // The conffile is no longer shipped and is removed on upgrade
+	Hash            string // Digest of the conffile as installed, only in the dpkg database
+	Obsolete        bool   // The conffile is no longer shipped, only in the dpkg database
+}
+
+// ControlArchive represents the content of the control archive of a package, with the
+// well-known members exposed as fields; absent members are left empty
+// https://www.debian.org/doc/debian-policy/ch-maintainerscripts.html
+type ControlArchive struct {
+	Control *DebPackage
+
+	// Maintainer scripts
+	Preinst  []byte
+	Postinst []byte
+	Prerm    []byte
+	Postrm   []byte
+	Config   []byte
+
+	Conffiles []Conffile
+	MD5Sums   map[string]string // Digests indexed by path, relative to the root directory
+	Triggers  []byte
+	Shlibs    []byte
+	Symbols   []byte
+	Templates []byte
+
+	// Files holds every member of the archive, in order
+	Files []ControlFile
+}
+
+// Control reads the control archive of a Debian package, like dpkg -e
+func (d *Dpkg) Control(debFile string) (*ControlArchive, error) {
+	var archive *ControlArchive
+
+	err := openDebFile(debFile, func(a *DebArchive) error {
+		var err error
+		archive, err = a.Control()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	archive.Control.Set("Filename", debFile)
+	return archive, nil
+}
+
+// readControlArchive reads every member of an uncompressed control archive
+func readControlArchive(uncompressedReader io.Reader) (*ControlArchive, error) {
+	archive := &ControlArchive{}
+
+	tarReader := tar.NewReader(uncompressedReader)
+	for {
+		tarHeader, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("go-apt/dpkg: failed to read tar header: %w", err)
+		}
+
+		// Only the regular files at the top of the archive are control members
+		name := strings.TrimPrefix(tarHeader.Name, "./")
+		if tarHeader.Typeflag != tar.TypeReg || name == "" || strings.Contains(name, "/") {
+			continue
+		}
+
+		data, err := io.ReadAll(tarReader)
+		if err != nil {
+			return nil, fmt.Errorf("go-apt/dpkg: failed to read %s: %w", name, err)
+		}
+		archive.Files = append(archive.Files, ControlFile{Name: name, Mode: tarHeader.FileInfo().Mode().Perm(), Data: data})
+
+		if err := archive.setMember(name, data); err != nil {
+			return nil, err
+		}
+	}
+
+	if archive.Control == nil {
+		return nil, ErrNoControlFile
+	}
+	return archive, nil
+}
+
+// setMember sets the field of a well-known member
+func (ca *ControlArchive) setMember(name string, data []byte) error {
+	var err error
+
+	switch name {
+	case "control":
+		ca.Control, err = parseControlFile(bytes.NewReader(data))
+	case "preinst":
+		ca.Preinst = data
+	case "postinst":
+		ca.Postinst = data
+	case "prerm":
+		ca.Prerm = data
+	case "postrm":
+		ca.Postrm = data
+	case "config":
+		ca.Config = data
+	case "conffiles":
+		ca.Conffiles, err = ParseConffiles(bytes.NewReader(data))
+	case "md5sums":
+		ca.MD5Sums, err = ParseMD5Sums(bytes.NewReader(data))
+	case "triggers":
+		ca.Triggers = data
+	case "shlibs":
+		ca.Shlibs = data
+	case "symbols":
+		ca.Symbols = data
+	case "templates":
+		ca.Templates = data
+	}
+
+	return err
+}
+
+// ExtractTo writes the members of the control archive into dir with their permissions,
+// creating dir if needed
+func (ca *ControlArchive) ExtractTo(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	for _, file := range ca.Files {
+		dest := filepath.Join(dir, file.Name)
+		if err := removeExisting(dest, false); err != nil {
+			return err
+		}
+		if err := os.WriteFile(dest, file.Data, file.Mode); err != nil {
+			return err
+		}
+		// The permissions given to WriteFile are subject to the umask
+		if err := os.Chmod(dest, file.Mode); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ParseConffiles parses a conffiles control member, one absolute path per line,
+// optionally preceded by the remove-on-upgrade flag
+func ParseConffiles(r io.Reader) ([]Conffile, error) {
+	var conffiles []Conffile
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var conffile Conffile
+		if rest, ok := strings.CutPrefix(line, "remove-on-upgrade "); ok {
+			conffile.RemoveOnUpgrade = true
+			line = strings.TrimSpace(rest)
+		}
+		if !strings.HasPrefix(line, "/") {
+			return nil, fmt.Errorf("go-apt/dpkg: invalid conffiles line %q", line)
+		}
+		conffile.Path = line
+
+		conffiles = append(conffiles, conffile)
+	}
+
+	return conffiles, scanner.Err()
+}
+
+// ParseMD5Sums parses a md5sums file in the format of md5sum, as found in control archives
+// and in the dpkg database, and returns the digests indexed by path
+func ParseMD5Sums(r io.Reader) (map[string]string, error) {
+	sums := make(map[string]string)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		digest, filename, ok := strings.Cut(line, " ")
+		// The file name is separated by two spaces, or by " *" in binary mode
+		filename = strings.TrimPrefix(strings.TrimLeft(filename, " "), "*")
+		if !ok || len(digest) != 32 || !isHexDigits(digest) || filename == "" {
+			return nil, fmt.Errorf("go-apt/dpkg: invalid md5sums line %q", line)
+		}
+
+		sums[strings.TrimPrefix(filename, "/")] = strings.ToLower(digest)
+	}
+
+	return sums, scanner.Err()
+}
+
+// isHexDigits checks if s only contains hexadecimal digits
+func isHexDigits(s string) bool {
+	for _, c := range []byte(s) {
+		if !isDigit(c) && (c < 'a' || c > 'f') && (c < 'A' || c > 'F') {
+			return false
+		}
+	}
+	return true
+}
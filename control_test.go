@@ -0,0 +1,108 @@
+package dpkg
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestControl tests the Control method of Dpkg
+func TestControl(t *testing.T) {
+	d := Dpkg{}
+	archive, err := d.Control("testdata/debs/vim-tiny_7.0-122+1etch5_amd64.deb")
+	if err != nil {
+		t.Fatalf("Control failed: %v", err)
+	}
+
+	if archive.Control.Name() != "vim-tiny" {
+		t.Errorf("Control.Name() = %q; want vim-tiny", archive.Control.Name())
+	}
+	for name, script := range map[string][]byte{"preinst": archive.Preinst, "postinst": archive.Postinst, "prerm": archive.Prerm} {
+		if !strings.HasPrefix(string(script), "#!/bin/bash") {
+			t.Errorf("%s = %.20q; want a shell script", name, script)
+		}
+	}
+	if archive.Postrm != nil || archive.Config != nil || archive.Conffiles != nil {
+		t.Errorf("Absent members should be empty")
+	}
+	if got := archive.MD5Sums["usr/bin/vim.tiny"]; got != "cc899e24af0eeb4f2ecc36d5eabd6ec4" {
+		t.Errorf("MD5Sums[usr/bin/vim.tiny] = %q", got)
+	}
+
+	var names []string
+	for _, file := range archive.Files {
+		names = append(names, file.Name)
+	}
+	if want := []string{"preinst", "prerm", "postinst", "control", "md5sums"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("Files = %v; want %v", names, want)
+	}
+}
+
+// TestControlExtractTo tests the ExtractTo method of ControlArchive
+func TestControlExtractTo(t *testing.T) {
+	d := Dpkg{}
+	archive, err := d.Control("testdata/debs/vim-tiny_9.1.1113-1_amd64.deb")
+	if err != nil {
+		t.Fatalf("Control failed: %v", err)
+	}
+
+	dir := filepath.Join(t.TempDir(), "DEBIAN")
+	if err := archive.ExtractTo(dir); err != nil {
+		t.Fatalf("ExtractTo failed: %v", err)
+	}
+
+	for _, file := range archive.Files {
+		info, err := os.Stat(filepath.Join(dir, file.Name))
+		if err != nil {
+			t.Errorf("Failed to stat %s: %v", file.Name, err)
+			continue
+		}
+		if info.Mode().Perm() != file.Mode || info.Size() != int64(len(file.Data)) {
+			t.Errorf("%s mode = %v, size = %d; want %v, %d", file.Name, info.Mode(), info.Size(), file.Mode, len(file.Data))
+		}
+	}
+}
+
+// TestParseConffiles tests the ParseConffiles function
+func TestParseConffiles(t *testing.T) {
+	conffiles, err := ParseConffiles(strings.NewReader("/etc/vim/vimrc.tiny\n\nremove-on-upgrade /etc/vim/old\n"))
+	if err != nil {
+		t.Fatalf("ParseConffiles failed: %v", err)
+	}
+
+	want := []Conffile{{Path: "/etc/vim/vimrc.tiny"}, {Path: "/etc/vim/old", RemoveOnUpgrade: true}}
+	if !reflect.DeepEqual(conffiles, want) {
+		t.Errorf("ParseConffiles() = %+v; want %+v", conffiles, want)
+	}
+
+	if _, err := ParseConffiles(strings.NewReader("etc/relative\n")); err == nil {
+		t.Errorf("Expected an error for a relative path")
+	}
+}
+
+// TestParseMD5Sums tests the ParseMD5Sums function
+func TestParseMD5Sums(t *testing.T) {
+	input := "cc899e24af0eeb4f2ecc36d5eabd6ec4  usr/bin/vim.tiny\n" +
+		"7DDCD83DA843E556396A5F1691029752 *usr/share/doc/with space\n"
+
+	sums, err := ParseMD5Sums(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseMD5Sums failed: %v", err)
+	}
+
+	want := map[string]string{
+		"usr/bin/vim.tiny":         "cc899e24af0eeb4f2ecc36d5eabd6ec4",
+		"usr/share/doc/with space": "7ddcd83da843e556396a5f1691029752",
+	}
+	if !reflect.DeepEqual(sums, want) {
+		t.Errorf("ParseMD5Sums() = %v; want %v", sums, want)
+	}
+
+	for _, line := range []string{"cc899e24  usr/bin/vim.tiny", "zz899e24af0eeb4f2ecc36d5eabd6ec4  usr/bin/vim.tiny", "cc899e24af0eeb4f2ecc36d5eabd6ec4"} {
+		if _, err := ParseMD5Sums(strings.NewReader(line)); err == nil {
+			t.Errorf("ParseMD5Sums(%q) expected an error", line)
+		}
+	}
+}
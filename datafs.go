@@ -0,0 +1,330 @@
+package dpkg
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+	"time"
+)
+
+// maxSymlinks is the maximum number of symlinks followed when opening a file, like Linux
+const maxSymlinks = 40
+
+// dataNode represents an entry of the data archive in the file system view
+type dataNode struct {
+	entry    DataEntry
+	data     []byte // Content of a regular file, when it was loaded
+	loaded   bool
+	children []string
+}
+
+// dataIndex represents the entries of the data archive, indexed by cleaned path
+type dataIndex map[string]*dataNode
+
+// Verify that DebArchive implements the file system interfaces
+var (
+	_ fs.FS     = (*DebArchive)(nil)
+	_ fs.StatFS = (*DebArchive)(nil)
+)
+
+// Index reads the data archive once and keeps its content in memory, so the following
+// calls to Open and Stat do not decompress it again
+func (a *DebArchive) Index() error {
+	index, err := a.scanData("", true)
+	if err != nil {
+		return err
+	}
+	a.index = index
+	return nil
+}
+
+// Open opens the named file of the data archive, implementing fs.FS. Paths are relative
+// to the root of the package, e.g. "usr/bin/vim.tiny", and symlinks are followed inside
+// the package. Without an index, the data archive is read again on every call.
+func (a *DebArchive) Open(name string) (fs.File, error) {
+	index, node, err := a.lookup("open", name, true)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &dataFileInfo{name: path.Base(name), entry: node.entry}
+	if node.entry.Type == EntryDirectory {
+		entries := make([]fs.DirEntry, len(node.children))
+		for i, child := range node.children {
+			entries[i] = fs.FileInfoToDirEntry(&dataFileInfo{name: path.Base(child), entry: index[child].entry})
+		}
+		return &dataDir{info: info, entries: entries}, nil
+	}
+
+	return &dataFile{info: info, Reader: bytes.NewReader(node.data)}, nil
+}
+
+// Stat returns the information of the named file, following symlinks, implementing fs.StatFS
+func (a *DebArchive) Stat(name string) (fs.FileInfo, error) {
+	_, node, err := a.lookup("stat", name, true)
+	if err != nil {
+		return nil, err
+	}
+	return &dataFileInfo{name: path.Base(name), entry: node.entry}, nil
+}
+
+// Lstat returns the information of the named file, without following a final symlink
+func (a *DebArchive) Lstat(name string) (fs.FileInfo, error) {
+	_, node, err := a.lookup("lstat", name, false)
+	if err != nil {
+		return nil, err
+	}
+	return &dataFileInfo{name: path.Base(name), entry: node.entry}, nil
+}
+
+// ReadLink returns the target of the named symlink
+func (a *DebArchive) ReadLink(name string) (string, error) {
+	_, node, err := a.lookup("readlink", name, false)
+	if err != nil {
+		return "", err
+	}
+	if node.entry.Type != EntrySymlink {
+		return "", &fs.PathError{Op: "readlink", Path: name, Err: fs.ErrInvalid}
+	}
+	return node.entry.LinkTarget, nil
+}
+
+// lookup returns the index and the node of the named file; when follow is set, a final
+// symlink is followed and the content of a regular file is loaded
+func (a *DebArchive) lookup(op, name string, follow bool) (dataIndex, *dataNode, error) {
+	if !fs.ValidPath(name) {
+		return nil, nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+
+	index := a.index
+	if index == nil {
+		var err error
+		if index, err = a.scanData(name, false); err != nil {
+			return nil, nil, &fs.PathError{Op: op, Path: name, Err: err}
+		}
+	}
+
+	for hops := 0; ; hops++ {
+		resolved, err := index.resolve(name)
+		if err != nil {
+			return nil, nil, &fs.PathError{Op: op, Path: name, Err: err}
+		}
+		node := index[resolved]
+
+		if follow && node.entry.Type == EntrySymlink {
+			if hops == maxSymlinks {
+				return nil, nil, &fs.PathError{Op: op, Path: name, Err: errors.New("too many levels of symbolic links")}
+			}
+			name = symlinkTarget(resolved, node.entry.LinkTarget)
+			continue
+		}
+
+		// The content was not loaded by the first scan when the name went through a
+		// symlink, or when the file is a hardlink
+		if follow && !node.loaded && (node.entry.Type == EntryRegular || node.entry.Type == EntryHardlink) {
+			load := resolved
+			if node.entry.Type == EntryHardlink {
+				load = path.Clean(strings.TrimPrefix(strings.TrimPrefix(node.entry.LinkTarget, "./"), "/"))
+			}
+			if index, err = a.scanData(load, false); err != nil {
+				return nil, nil, &fs.PathError{Op: op, Path: name, Err: err}
+			}
+			node = index[resolved]
+		}
+
+		return index, node, nil
+	}
+}
+
+// scanData reads the data archive and indexes its entries; the content of the regular
+// files is loaded for the named file only, or for every file when all is set
+func (a *DebArchive) scanData(name string, all bool) (dataIndex, error) {
+	uncompressedData, err := a.Data()
+	if err != nil {
+		return nil, err
+	}
+	defer uncompressedData.Close()
+
+	index := dataIndex{".": {entry: DataEntry{Path: ".", Type: EntryDirectory, Mode: 0755}}}
+	err = walkDataArchive(uncompressedData, func(header *tar.Header, r io.Reader) error {
+		entry, err := newDataEntry(header)
+		if err != nil {
+			return err
+		}
+		key, err := cleanArchivePath(strings.TrimPrefix(entry.Path, "/"))
+		if err != nil {
+			return err
+		}
+
+		node := &dataNode{entry: entry}
+		if entry.Type == EntryRegular && (all || key == name) {
+			if node.data, err = io.ReadAll(r); err != nil {
+				return err
+			}
+			node.loaded = true
+		}
+		index[key] = node
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	index.link()
+	return index, nil
+}
+
+// link adds the missing parent directories, the children of the directories, and
+// the content of the hardlinks
+func (index dataIndex) link() {
+	for key := range index {
+		for child := key; child != "."; {
+			parent := path.Dir(child)
+			if _, ok := index[parent]; !ok {
+				index[parent] = &dataNode{entry: DataEntry{Path: parent, Type: EntryDirectory, Mode: 0755}}
+			}
+			child = parent
+		}
+	}
+
+	for key := range index {
+		if key != "." {
+			parent := index[path.Dir(key)]
+			parent.children = append(parent.children, key)
+		}
+	}
+
+	for _, node := range index {
+		slices.Sort(node.children)
+
+		// Hardlinks are regular files sharing the content of their target
+		if node.entry.Type == EntryHardlink {
+			target, err := cleanArchivePath(strings.TrimPrefix(node.entry.LinkTarget, "/"))
+			if targetNode, ok := index[target]; err == nil && ok && targetNode.entry.Type == EntryRegular {
+				node.entry.Size = targetNode.entry.Size
+				node.data, node.loaded = targetNode.data, targetNode.loaded
+			}
+		}
+	}
+}
+
+// resolve returns the key of the named file, following the symlinks of its parent directories
+func (index dataIndex) resolve(name string) (string, error) {
+	current, remaining := ".", name
+	for hops := 0; remaining != "."; {
+		part, rest, _ := strings.Cut(remaining, "/")
+		key := path.Join(current, part)
+		node, ok := index[key]
+		if !ok {
+			return "", fs.ErrNotExist
+		}
+
+		// The last component is returned as it is, intermediate symlinks are followed
+		if rest == "" {
+			return key, nil
+		}
+		switch node.entry.Type {
+		case EntryDirectory:
+			current, remaining = key, rest
+		case EntrySymlink:
+			if hops++; hops > maxSymlinks {
+				return "", errors.New("too many levels of symbolic links")
+			}
+			current, remaining = ".", path.Join(symlinkTarget(key, node.entry.LinkTarget), rest)
+		default:
+			return "", fs.ErrNotExist
+		}
+	}
+
+	return current, nil
+}
+
+// symlinkTarget returns the path of the target of a symlink, relative to the root of the
+// package; absolute targets and targets above the root are resolved from the root
+func symlinkTarget(name, target string) string {
+	if path.IsAbs(target) {
+		return path.Clean(strings.TrimPrefix(target, "/"))
+	}
+	resolved := path.Join(path.Dir(name), target)
+	for resolved == ".." || strings.HasPrefix(resolved, "../") {
+		resolved = strings.TrimPrefix(strings.TrimPrefix(resolved, ".."), "/")
+	}
+	if resolved == "" {
+		return "."
+	}
+	return resolved
+}
+
+// dataFileInfo implements fs.FileInfo for the entries of the data archive
+type dataFileInfo struct {
+	name  string
+	entry DataEntry
+}
+
+func (fi *dataFileInfo) Name() string       { return fi.name }
+func (fi *dataFileInfo) Size() int64        { return fi.entry.Size }
+func (fi *dataFileInfo) ModTime() time.Time { return fi.entry.ModTime }
+func (fi *dataFileInfo) IsDir() bool        { return fi.entry.Type == EntryDirectory }
+func (fi *dataFileInfo) Sys() any           { return fi.entry }
+
+// Mode returns the permissions and the type bits of the entry
+func (fi *dataFileInfo) Mode() fs.FileMode {
+	mode := fi.entry.Mode
+	switch fi.entry.Type {
+	case EntryDirectory:
+		mode |= fs.ModeDir
+	case EntrySymlink:
+		mode |= fs.ModeSymlink
+	case EntryCharDevice:
+		mode |= fs.ModeDevice | fs.ModeCharDevice
+	case EntryBlockDevice:
+		mode |= fs.ModeDevice
+	case EntryFIFO:
+		mode |= fs.ModeNamedPipe
+	}
+	return mode
+}
+
+// dataFile implements fs.File for the regular files of the data archive
+type dataFile struct {
+	*bytes.Reader
+	info *dataFileInfo
+}
+
+func (f *dataFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *dataFile) Close() error               { return nil }
+
+// dataDir implements fs.ReadDirFile for the directories of the data archive
+type dataDir struct {
+	info    *dataFileInfo
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *dataDir) Stat() (fs.FileInfo, error) { return d.info, nil }
+func (d *dataDir) Close() error               { return nil }
+
+// Read returns an error, as directories cannot be read
+func (d *dataDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.info.name, Err: errors.New("is a directory")}
+}
+
+// ReadDir returns the next n entries of the directory, or all of them when n <= 0
+func (d *dataDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	remaining := d.entries[d.offset:]
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return remaining, nil
+	}
+	if len(remaining) == 0 {
+		return nil, io.EOF
+	}
+	n = min(n, len(remaining))
+	d.offset += n
+	return remaining[:n], nil
+}
@@ -0,0 +1,119 @@
+package dpkg
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"slices"
+	"testing"
+	"testing/fstest"
+)
+
+// openTestDeb opens a package of the testdata directory with OpenDeb
+func openTestDeb(t *testing.T, filePath string) *DebArchive {
+	t.Helper()
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", filePath, err)
+	}
+	a, err := OpenDeb(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("OpenDeb(%s) failed: %v", filePath, err)
+	}
+	return a
+}
+
+// TestDebArchiveFS tests the fs.FS implementation of DebArchive
+func TestDebArchiveFS(t *testing.T) {
+	a := openTestDeb(t, "testdata/debs/vim-tiny_9.1.1113-1_amd64.deb")
+
+	content, err := fs.ReadFile(a, "etc/vim/vimrc.tiny")
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	if len(content) != 662 {
+		t.Errorf("ReadFile(etc/vim/vimrc.tiny) returned %d bytes; want 662", len(content))
+	}
+
+	matches, err := fs.Glob(a, "usr/bin/*")
+	if err != nil || !slices.Equal(matches, []string{"usr/bin/vim.tiny"}) {
+		t.Errorf("Glob(usr/bin/*) = %v, %v", matches, err)
+	}
+
+	if _, err := a.Open("usr/bin/missing"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Open(usr/bin/missing) error = %v; want ErrNotExist", err)
+	}
+	if _, err := a.Open("./usr"); !errors.Is(err, fs.ErrInvalid) {
+		t.Errorf("Open(./usr) error = %v; want ErrInvalid", err)
+	}
+
+	if err := a.Index(); err != nil {
+		t.Fatalf("Index failed: %v", err)
+	}
+	if err := fstest.TestFS(a, "etc/vim/vimrc.tiny", "usr/bin/vim.tiny", "usr/share/doc/vim-tiny/copyright"); err != nil {
+		t.Errorf("TestFS failed: %v", err)
+	}
+}
+
+// TestDebArchiveFSSymlinks tests that symlinks are reported and followed inside the package
+func TestDebArchiveFSSymlinks(t *testing.T) {
+	a := openTestDeb(t, "testdata/debs/vim-tiny_7.0-122+1etch5_amd64.deb")
+
+	info, err := a.Lstat("usr/share/doc/vim-tiny")
+	if err != nil || info.Mode()&fs.ModeSymlink == 0 {
+		t.Errorf("Lstat(usr/share/doc/vim-tiny) = %v, %v; want a symlink", info, err)
+	}
+	if target, err := a.ReadLink("usr/share/doc/vim-tiny"); err != nil || target != "vim-common" {
+		t.Errorf("ReadLink(usr/share/doc/vim-tiny) = %q, %v; want vim-common", target, err)
+	}
+	// The target of the symlink is shipped by another package
+	if _, err := a.Stat("usr/share/doc/vim-tiny"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Stat(usr/share/doc/vim-tiny) error = %v; want ErrNotExist", err)
+	}
+
+	var symlinks []string
+	err = fs.WalkDir(a, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type()&fs.ModeSymlink != 0 {
+			symlinks = append(symlinks, name)
+		}
+		return nil
+	})
+	if err != nil || !slices.Contains(symlinks, "usr/share/doc/vim-tiny") {
+		t.Errorf("WalkDir found symlinks %v, %v", symlinks, err)
+	}
+}
+
+// TestDebArchiveFSLinks tests reading through symlinks and hardlinks
+func TestDebArchiveFSLinks(t *testing.T) {
+	b := newTestBuilder()
+	b.Files = append(b.Files,
+		BuildFile{DataEntry: DataEntry{Path: "usr/lib/hello", Type: EntrySymlink, LinkTarget: "../bin"}},
+		BuildFile{DataEntry: DataEntry{Path: "usr/sbin/hello", Type: EntrySymlink, LinkTarget: "/usr/bin/hello"}},
+	)
+
+	var buf bytes.Buffer
+	if err := b.Build(&buf); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	a, err := OpenDeb(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("OpenDeb failed: %v", err)
+	}
+
+	want := "#!/bin/sh\necho hello\n"
+	for _, name := range []string{"usr/bin/hello", "usr/bin/hi", "usr/bin/greet", "usr/lib/hello/hello", "usr/lib/hello/hi", "usr/sbin/hello"} {
+		content, err := fs.ReadFile(a, name)
+		if err != nil || string(content) != want {
+			t.Errorf("ReadFile(%s) = %q, %v; want %q", name, content, err, want)
+		}
+	}
+
+	if info, err := a.Stat("usr/lib/hello"); err != nil || !info.IsDir() {
+		t.Errorf("Stat(usr/lib/hello) = %v, %v; want a directory", info, err)
+	}
+}
@@ -0,0 +1,255 @@
+package dpkg
+
+import (
+	"archive/tar"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/blakesmith/ar"
+)
+
+// arMagic is the global header of ar archives
+const arMagic = "!<arch>\n"
+
+// debMember represents the position of a member in the ar archive
+type debMember struct {
+	name   string
+	offset int64
+	size   int64
+}
+
+// DebArchive represents a Debian package opened for random access; its members are
+// only read and decompressed when they are requested
+type DebArchive struct {
+	r       io.ReaderAt
+	size    int64
+	members []debMember
+	index   dataIndex // Content of the data archive, see Index
+}
+
+// OpenDeb opens a Debian package of the given size from r, reading only the ar headers
+func OpenDeb(r io.ReaderAt, size int64) (*DebArchive, error) {
+	magic := make([]byte, len(arMagic))
+	if _, err := r.ReadAt(magic, 0); err != nil || string(magic) != arMagic {
+		return nil, ErrDebHeader
+	}
+
+	a := &DebArchive{r: r, size: size}
+
+	// The section reader is seekable, so the ar reader skips the content of the members
+	section := io.NewSectionReader(r, 0, size)
+	reader := ar.NewReader(section)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("go-apt/dpkg: failed to read ar header: %w", err)
+		}
+
+		offset, err := section.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, err
+		}
+		if header.Size < 0 || offset+header.Size > size {
+			return nil, fmt.Errorf("go-apt/dpkg: truncated ar member %s", header.Name)
+		}
+
+		// GNU ar terminates the member names with a slash
+		name := strings.TrimSuffix(header.Name, "/")
+		a.members = append(a.members, debMember{name: name, offset: offset, size: header.Size})
+	}
+
+	return a, nil
+}
+
+// Size returns the size of the package
+func (a *DebArchive) Size() int64 {
+	return a.size
+}
+
+// Members returns the names of the ar members, e.g. debian-binary, control.tar.xz and data.tar.xz
+func (a *DebArchive) Members() []string {
+	names := make([]string, len(a.members))
+	for i, member := range a.members {
+		names[i] = member.name
+	}
+	return names
+}
+
+// Info reads the control file of the package
+func (a *DebArchive) Info() (*DebPackage, error) {
+	var pkg *DebPackage
+
+	err := a.readMember("control.tar", ErrNoControlFile, func(name string, r io.Reader) error {
+		var err error
+		pkg, err = extractControlFile(name, r)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return pkg, nil
+}
+
+// Control reads every member of the control archive of the package
+func (a *DebArchive) Control() (*ControlArchive, error) {
+	var archive *ControlArchive
+
+	err := a.readMember("control.tar", ErrNoControlFile, func(name string, r io.Reader) error {
+		uncompressedData, err := decompressMember(name, r)
+		if err != nil {
+			return err
+		}
+		defer uncompressedData.Close()
+
+		archive, err = readControlArchive(uncompressedData)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return archive, nil
+}
+
+// Data returns the decompressed data archive of the package, as a tar stream
+func (a *DebArchive) Data() (io.ReadCloser, error) {
+	member, ok := a.member("data.tar")
+	if !ok {
+		return nil, ErrNoDataFile
+	}
+	return decompressMember(member.name, io.NewSectionReader(a.r, member.offset, member.size))
+}
+
+// Contents lists the entries of the data archive of the package
+func (a *DebArchive) Contents() ([]DataEntry, error) {
+	uncompressedData, err := a.Data()
+	if err != nil {
+		return nil, err
+	}
+	defer uncompressedData.Close()
+
+	var entries []DataEntry
+	err = walkDataArchive(uncompressedData, func(header *tar.Header, _ io.Reader) error {
+		entry, err := newDataEntry(header)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, entry)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// Extract extracts the data archive of the package into targetDir, see Dpkg.Extract
+func (a *DebArchive) Extract(targetDir string, opts ExtractOptions) ([]DataEntry, error) {
+	uncompressedData, err := a.Data()
+	if err != nil {
+		return nil, err
+	}
+	defer uncompressedData.Close()
+
+	return extractDataArchive(uncompressedData, targetDir, opts)
+}
+
+// member returns the first member whose name starts with prefix
+func (a *DebArchive) member(prefix string) (debMember, bool) {
+	for _, member := range a.members {
+		if strings.HasPrefix(member.name, prefix) {
+			return member, true
+		}
+	}
+	return debMember{}, false
+}
+
+// readMember calls fn with the name and content of the first member whose name starts
+// with prefix, returning notFound if there is none
+func (a *DebArchive) readMember(prefix string, notFound error, fn func(name string, r io.Reader) error) error {
+	member, ok := a.member(prefix)
+	if !ok {
+		return notFound
+	}
+	return fn(member.name, io.NewSectionReader(a.r, member.offset, member.size))
+}
+
+// openDebFile opens a .deb file and calls fn with its DebArchive
+func openDebFile(debFile string, fn func(a *DebArchive) error) error {
+	file, err := os.Open(debFile)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	a, err := OpenDeb(file, info.Size())
+	if err != nil {
+		return err
+	}
+	return fn(a)
+}
+
+// InfoFromReader reads the control file of a Debian package from a stream, e.g. an
+// HTTP download, and sets the Size, MD5, SHA1 and SHA256 fields computed while reading it
+func (d *Dpkg) InfoFromReader(r io.Reader) (*DebPackage, error) {
+	hasher := newPackageHasher()
+	tee := io.TeeReader(r, hasher)
+
+	magic := make([]byte, len(arMagic))
+	if _, err := io.ReadFull(tee, magic); err != nil || string(magic) != arMagic {
+		return nil, ErrDebHeader
+	}
+	// The ar reader skips the global header, which was already consumed
+	reader := ar.NewReader(io.MultiReader(bytes.NewReader(magic), tee))
+
+	var pkg *DebPackage
+	for pkg == nil {
+		header, err := reader.Next()
+		if err == io.EOF {
+			return nil, ErrNoControlFile
+		}
+		if err != nil {
+			return nil, fmt.Errorf("go-apt/dpkg: failed to read ar header: %w", err)
+		}
+
+		if strings.HasPrefix(header.Name, "control.tar") {
+			if pkg, err = extractControlFile(strings.TrimSuffix(header.Name, "/"), reader); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// The rest of the package is read to complete the hashes
+	if _, err := io.Copy(io.Discard, tee); err != nil {
+		return nil, err
+	}
+
+	hasher.apply(pkg)
+	pkg.Set("Size", strconv.FormatInt(hasher.size, 10))
+	return pkg, nil
+}
+
+// IsDeb checks if the stream starts with the header of a .deb package
+func (d *Dpkg) IsDeb(r io.Reader) bool {
+	magicValue := []byte(arMagic + "debian-binary")
+	magic := make([]byte, len(magicValue))
+
+	if _, err := io.ReadFull(r, magic); err != nil {
+		return false
+	}
+	return bytes.Equal(magic, magicValue)
+}
@@ -0,0 +1,122 @@
+package dpkg
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+// TestOpenDeb tests the OpenDeb function
+func TestOpenDeb(t *testing.T) {
+	tests := []struct {
+		filePath string
+		members  []string
+	}{
+		{"testdata/debs/vim-tiny_9.1.1113-1_amd64.deb", []string{"debian-binary", "control.tar.xz", "data.tar.xz"}},
+		{"testdata/debs/vim-tiny_7.0-122+1etch5_amd64.deb", []string{"debian-binary", "control.tar.gz", "data.tar.gz"}},
+	}
+
+	for _, test := range tests {
+		data, err := os.ReadFile(test.filePath)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", test.filePath, err)
+		}
+
+		a, err := OpenDeb(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			t.Fatalf("OpenDeb(%s) failed: %v", test.filePath, err)
+		}
+		if !slices.Equal(a.Members(), test.members) {
+			t.Errorf("Members() = %v; want %v", a.Members(), test.members)
+		}
+		if a.Size() != int64(len(data)) {
+			t.Errorf("Size() = %d; want %d", a.Size(), len(data))
+		}
+
+		pkg, err := a.Info()
+		if err != nil {
+			t.Fatalf("Info failed: %v", err)
+		}
+		if pkg.Name() != "vim-tiny" {
+			t.Errorf("Info().Name() = %q; want vim-tiny", pkg.Name())
+		}
+
+		entries, err := a.Contents()
+		if err != nil {
+			t.Fatalf("Contents failed: %v", err)
+		}
+		d := Dpkg{}
+		fromFile, err := d.Contents(test.filePath)
+		if err != nil {
+			t.Fatalf("Contents failed: %v", err)
+		}
+		if len(entries) == 0 || len(entries) != len(fromFile) {
+			t.Errorf("Contents() returned %d entries; want %d", len(entries), len(fromFile))
+		}
+	}
+}
+
+// TestOpenDebErrors tests the errors reported by OpenDeb
+func TestOpenDebErrors(t *testing.T) {
+	if _, err := OpenDeb(bytes.NewReader([]byte("not a package")), 13); !errors.Is(err, ErrDebHeader) {
+		t.Errorf("OpenDeb error = %v; want ErrDebHeader", err)
+	}
+
+	data, err := os.ReadFile("testdata/debs/vim-tiny_9.1.1113-1_amd64.deb")
+	if err != nil {
+		t.Fatalf("Failed to read package: %v", err)
+	}
+	truncated := data[:len(data)/2]
+	if _, err := OpenDeb(bytes.NewReader(truncated), int64(len(truncated))); err == nil {
+		t.Errorf("Expected an error for a truncated package")
+	}
+}
+
+// TestInfoFromReader tests the InfoFromReader method of Dpkg
+func TestInfoFromReader(t *testing.T) {
+	filePath := "testdata/debs/vim-tiny_9.1.1113-1_amd64.deb"
+	file, err := os.Open(filePath)
+	if err != nil {
+		t.Fatalf("Failed to open %s: %v", filePath, err)
+	}
+	defer file.Close()
+
+	d := Dpkg{}
+	pkg, err := d.InfoFromReader(file)
+	if err != nil {
+		t.Fatalf("InfoFromReader failed: %v", err)
+	}
+	if pkg.Name() != "vim-tiny" || pkg.HasFilename() {
+		t.Errorf("InfoFromReader() = %s", pkg.Name())
+	}
+
+	// The hashes match the ones calculated from the file
+	expected := &DebPackage{}
+	expected.Set("Filename", filePath)
+	if err := expected.CalculateAllHashes(); err != nil {
+		t.Fatalf("CalculateAllHashes failed: %v", err)
+	}
+	for _, field := range []string{"MD5", "SHA1", "SHA256"} {
+		if pkg.Get(field) == "" || pkg.Get(field) != expected.Get(field) {
+			t.Errorf("%s = %q; want %q", field, pkg.Get(field), expected.Get(field))
+		}
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		t.Fatalf("Failed to stat %s: %v", filePath, err)
+	}
+	if pkg.Get("Size") != strconv.FormatInt(info.Size(), 10) {
+		t.Errorf("Size = %q; want %d", pkg.Get("Size"), info.Size())
+	}
+
+	if _, err := d.InfoFromReader(bytes.NewReader([]byte("!<arch>\n"))); !errors.Is(err, ErrNoControlFile) {
+		t.Errorf("InfoFromReader error = %v; want ErrNoControlFile", err)
+	}
+	if _, err := d.InfoFromReader(bytes.NewReader([]byte("PK\x03\x04"))); !errors.Is(err, ErrDebHeader) {
+		t.Errorf("InfoFromReader error = %v; want ErrDebHeader", err)
+	}
+}
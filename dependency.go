@@ -0,0 +1,305 @@
+package dpkg
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Relationships represents a parsed relationship field such as Depends, Pre-Depends,
+// Recommends, Conflicts, Breaks or Provides, where all groups must be satisfied
+// https://www.debian.org/doc/debian-policy/ch-relationships.html
+type Relationships []Alternatives
+
+// Alternatives represents a group of relationships separated by "|", where any of them satisfies the group
+type Alternatives []Relationship
+
+// Relationship represents a single package in a relationship field, e.g.
+// "libc6:amd64 (>= 2.34) [amd64 !i386] <!nocheck>"
+type Relationship struct {
+	Name          string
+	Arch          string // Architecture qualifier such as "any" or "native"
+	Constraint    *VersionConstraint
+	Architectures []ArchRestriction
+	Profiles      [][]BuildProfile // Restriction formulas, any of the groups must match
+}
+
+// ArchRestriction represents an architecture in a restriction list, e.g. "!i386"
+type ArchRestriction struct {
+	Negated bool
+	Arch    string
+}
+
+// BuildProfile represents a build profile in a restriction formula, e.g. "!nocheck"
+type BuildProfile struct {
+	Negated bool
+	Name    string
+}
+
+// ParseRelationships parses the value of a relationship field
+func ParseRelationships(field string) (Relationships, error) {
+	var rels Relationships
+
+	for _, group := range strings.Split(field, ",") {
+		// Skip empty groups, e.g. a trailing comma
+		if strings.TrimSpace(group) == "" {
+			continue
+		}
+
+		var alts Alternatives
+		for _, alt := range strings.Split(group, "|") {
+			rel, err := ParseRelationship(alt)
+			if err != nil {
+				return nil, err
+			}
+			alts = append(alts, rel)
+		}
+		rels = append(rels, alts)
+	}
+
+	return rels, nil
+}
+
+// ParseRelationship parses a single alternative of a relationship field
+func ParseRelationship(alt string) (Relationship, error) {
+	var rel Relationship
+	s := strings.TrimSpace(alt)
+
+	invalid := func(format string, args ...any) error {
+		return fmt.Errorf("%w %q: %s", ErrInvalidRelationship, strings.TrimSpace(alt), fmt.Sprintf(format, args...))
+	}
+
+	if s == "" {
+		return rel, invalid("empty alternative")
+	}
+
+	// Package name
+	n := strings.IndexAny(s, " \t\n:([<")
+	if n < 0 {
+		n = len(s)
+	}
+	rel.Name = s[:n]
+	if !isValidPackageName(rel.Name) {
+		return rel, invalid("invalid package name %q", rel.Name)
+	}
+	s = s[n:]
+
+	// Architecture qualifier
+	if strings.HasPrefix(s, ":") {
+		n = strings.IndexAny(s, " \t\n([<")
+		if n < 0 {
+			n = len(s)
+		}
+		rel.Arch = s[1:n]
+		if !isValidArchName(rel.Arch) {
+			return rel, invalid("invalid architecture qualifier %q", rel.Arch)
+		}
+		s = s[n:]
+	}
+	s = strings.TrimSpace(s)
+
+	// Version constraint
+	if strings.HasPrefix(s, "(") {
+		end := strings.IndexByte(s, ')')
+		if end < 0 {
+			return rel, invalid("missing closing parenthesis")
+		}
+		vc, err := ParseVersionConstraint(s[:end+1])
+		if err != nil {
+			return rel, fmt.Errorf("%w %q: %w", ErrInvalidRelationship, strings.TrimSpace(alt), err)
+		}
+		rel.Constraint = &vc
+		s = strings.TrimSpace(s[end+1:])
+	}
+
+	// Architecture restriction list
+	if strings.HasPrefix(s, "[") {
+		end := strings.IndexByte(s, ']')
+		if end < 0 {
+			return rel, invalid("missing closing bracket")
+		}
+		for _, arch := range strings.Fields(s[1:end]) {
+			r := ArchRestriction{}
+			r.Negated, r.Arch = cutNegation(arch)
+			if !isValidArchName(r.Arch) {
+				return rel, invalid("invalid architecture %q", arch)
+			}
+			rel.Architectures = append(rel.Architectures, r)
+		}
+		if len(rel.Architectures) == 0 {
+			return rel, invalid("empty architecture list")
+		}
+		s = strings.TrimSpace(s[end+1:])
+	}
+
+	// Build profile restriction formulas
+	for strings.HasPrefix(s, "<") {
+		end := strings.IndexByte(s, '>')
+		if end < 0 {
+			return rel, invalid("missing closing angle bracket")
+		}
+		var profiles []BuildProfile
+		for _, profile := range strings.Fields(s[1:end]) {
+			p := BuildProfile{}
+			p.Negated, p.Name = cutNegation(profile)
+			if !isValidProfileName(p.Name) {
+				return rel, invalid("invalid build profile %q", profile)
+			}
+			profiles = append(profiles, p)
+		}
+		if len(profiles) == 0 {
+			return rel, invalid("empty build profile list")
+		}
+		rel.Profiles = append(rel.Profiles, profiles)
+		s = strings.TrimSpace(s[end+1:])
+	}
+
+	if s != "" {
+		return rel, invalid("unexpected trailing data %q", s)
+	}
+
+	return rel, nil
+}
+
+// String returns the relationship field in its canonical form
+func (rels Relationships) String() string {
+	groups := make([]string, len(rels))
+	for i, alts := range rels {
+		groups[i] = alts.String()
+	}
+	return strings.Join(groups, ", ")
+}
+
+// MarshalText implements the encoding.TextMarshaler interface
+func (rels Relationships) MarshalText() ([]byte, error) {
+	return []byte(rels.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface
+func (rels *Relationships) UnmarshalText(text []byte) error {
+	parsed, err := ParseRelationships(string(text))
+	if err != nil {
+		return err
+	}
+	*rels = parsed
+	return nil
+}
+
+// String returns the alternatives in their canonical form
+func (alts Alternatives) String() string {
+	parts := make([]string, len(alts))
+	for i, rel := range alts {
+		parts[i] = rel.String()
+	}
+	return strings.Join(parts, " | ")
+}
+
+// String returns the relationship in its canonical form
+func (rel Relationship) String() string {
+	var sb strings.Builder
+
+	sb.WriteString(rel.Name)
+	if rel.Arch != "" {
+		sb.WriteByte(':')
+		sb.WriteString(rel.Arch)
+	}
+
+	if rel.Constraint != nil {
+		sb.WriteByte(' ')
+		sb.WriteString(rel.Constraint.String())
+	}
+
+	if len(rel.Architectures) > 0 {
+		sb.WriteString(" [")
+		for i, r := range rel.Architectures {
+			if i > 0 {
+				sb.WriteByte(' ')
+			}
+			sb.WriteString(r.String())
+		}
+		sb.WriteByte(']')
+	}
+
+	for _, profiles := range rel.Profiles {
+		sb.WriteString(" <")
+		for i, p := range profiles {
+			if i > 0 {
+				sb.WriteByte(' ')
+			}
+			sb.WriteString(p.String())
+		}
+		sb.WriteByte('>')
+	}
+
+	return sb.String()
+}
+
+// String returns the architecture restriction, prefixed with "!" when negated
+func (r ArchRestriction) String() string {
+	if r.Negated {
+		return "!" + r.Arch
+	}
+	return r.Arch
+}
+
+// String returns the build profile, prefixed with "!" when negated
+func (p BuildProfile) String() string {
+	if p.Negated {
+		return "!" + p.Name
+	}
+	return p.Name
+}
+
+// cutNegation removes the "!" prefix of a restriction term
+func cutNegation(term string) (bool, string) {
+	if strings.HasPrefix(term, "!") {
+		return true, term[1:]
+	}
+	return false, term
+}
+
+// isValidPackageName checks the package name against the Debian policy rules, which
+// require at least two characters, a lowercase alphanumeric first character followed by
+// lowercase alphanumerics, "+", "-" or "."
+// https://www.debian.org/doc/debian-policy/ch-controlfields.html#source
+func isValidPackageName(name string) bool {
+	if len(name) < 2 || !isLowerAlnum(name[0]) {
+		return false
+	}
+	for i := 1; i < len(name); i++ {
+		if !isLowerAlnum(name[i]) && !strings.ContainsRune("+-.", rune(name[i])) {
+			return false
+		}
+	}
+	return true
+}
+
+// isLowerAlnum checks if the character is an ASCII digit or lowercase letter
+func isLowerAlnum(c byte) bool {
+	return isDigit(c) || (c >= 'a' && c <= 'z')
+}
+
+// isValidArchName checks if the name is a valid architecture or architecture wildcard
+func isValidArchName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		if !isAlnum(name[i]) && name[i] != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+// isValidProfileName checks if the name is a valid build profile name
+func isValidProfileName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		if !isAlnum(name[i]) && !strings.ContainsRune("+-.", rune(name[i])) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,100 @@
+package dpkg
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestParseRelationships tests the ParseRelationships function
+func TestParseRelationships(t *testing.T) {
+	tests := []struct {
+		field string
+		want  string
+	}{
+		{
+			"vim-common (= 2:9.1.1113-1), libacl1 (>= 2.2.23), libc6 (>= 2.34), libselinux1 (>= 3.1~), libtinfo6 (>= 6)",
+			"vim-common (= 2:9.1.1113-1), libacl1 (>= 2.2.23), libc6 (>= 2.34), libselinux1 (>= 3.1~), libtinfo6 (>= 6)",
+		},
+		{
+			"default-mta | mail-transport-agent, python3:any (>=3.11~)",
+			"default-mta | mail-transport-agent, python3:any (>= 3.11~)",
+		},
+		{
+			"libfoo-dev [amd64 !i386]  <!nocheck> <stage1 cross>,\n libbar:native",
+			"libfoo-dev [amd64 !i386] <!nocheck> <stage1 cross>, libbar:native",
+		},
+		{"editor", "editor"},
+		{"perl (< 5.10), ", "perl (<= 5.10)"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			rels, err := ParseRelationships(tt.field)
+			if err != nil {
+				t.Fatalf("ParseRelationships(%q) unexpected error: %v", tt.field, err)
+			}
+			if got := rels.String(); got != tt.want {
+				t.Errorf("ParseRelationships(%q).String() = %q; want %q", tt.field, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestParseRelationship tests the ParseRelationship function
+func TestParseRelationship(t *testing.T) {
+	rel, err := ParseRelationship("libc6:amd64 (>= 2.34) [amd64 !i386] <!nocheck> <stage1>")
+	if err != nil {
+		t.Fatalf("ParseRelationship unexpected error: %v", err)
+	}
+
+	if rel.Name != "libc6" || rel.Arch != "amd64" {
+		t.Errorf("Expected libc6:amd64, got %s:%s", rel.Name, rel.Arch)
+	}
+	if rel.Constraint == nil || rel.Constraint.Relation != RelationGE || rel.Constraint.Version.String() != "2.34" {
+		t.Errorf("Expected constraint (>= 2.34), got %v", rel.Constraint)
+	}
+	if len(rel.Architectures) != 2 || rel.Architectures[0] != (ArchRestriction{false, "amd64"}) || rel.Architectures[1] != (ArchRestriction{true, "i386"}) {
+		t.Errorf("Expected architectures [amd64 !i386], got %v", rel.Architectures)
+	}
+	if len(rel.Profiles) != 2 || rel.Profiles[0][0] != (BuildProfile{true, "nocheck"}) || rel.Profiles[1][0] != (BuildProfile{false, "stage1"}) {
+		t.Errorf("Expected profiles <!nocheck> <stage1>, got %v", rel.Profiles)
+	}
+}
+
+// TestParseRelationshipsErrors tests that errors point at the offending alternative
+func TestParseRelationshipsErrors(t *testing.T) {
+	tests := []struct {
+		field string
+		alt   string
+	}{
+		{"libc6, foo | | bar", `""`},
+		{"libc6, foo (>= 1.0", `"foo (>= 1.0"`},
+		{"libc6 (>= a), foo", `"libc6 (>= a)"`},
+		{"libc6, Foo_Bar", `"Foo_Bar"`},
+		{"libc6, Libc6", `"Libc6"`},
+		{"libc6, a", `"a"`},
+		{"libc6, -foo", `"-foo"`},
+		{"libc6, .foo", `".foo"`},
+		{"libc6 [amd64", `"libc6 [amd64"`},
+		{"libc6 [ ]", `"libc6 [ ]"`},
+		{"libc6 <!nocheck", `"libc6 <!nocheck"`},
+		{"libc6 <>", `"libc6 <>"`},
+		{"libc6:", `"libc6:"`},
+		{"libc6 extra", `"libc6 extra"`},
+		{"libc6 [amd64] (>= 1.0)", `"libc6 [amd64] (>= 1.0)"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			_, err := ParseRelationships(tt.field)
+			if !errors.Is(err, ErrInvalidRelationship) {
+				t.Fatalf("ParseRelationships(%q) error = %v; want ErrInvalidRelationship", tt.field, err)
+			}
+			if !strings.Contains(err.Error(), tt.alt) {
+				t.Errorf("ParseRelationships(%q) error = %q; want it to mention %s", tt.field, err, tt.alt)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package dpkg
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"strings"
+)
+
+// Description represents a parsed Description field, made of a single line synopsis
+// and an extended description split into paragraphs
+// https://www.debian.org/doc/debian-policy/ch-controlfields.html#description
+type Description struct {
+	Synopsis   string
+	Paragraphs []DescriptionParagraph
+}
+
+// DescriptionParagraph represents a paragraph of the extended description; lines of
+// regular paragraphs may be word-wrapped, while verbatim lines, which start with two or
+// more spaces in the control file, must be displayed as they are
+type DescriptionParagraph struct {
+	Verbatim bool
+	Lines    []string
+
+	adjacent bool // Not separated from the previous paragraph by an empty line
+}
+
+// ParseDescription parses the value of a Description field
+func ParseDescription(value string) Description {
+	lines := strings.Split(strings.TrimRight(value, "\n"), "\n")
+	d := Description{Synopsis: strings.TrimSpace(lines[0])}
+
+	var current *DescriptionParagraph
+	separated := true
+	for _, line := range lines[1:] {
+		// Remove the leading space of the continuation line
+		if line != "" && (line[0] == ' ' || line[0] == '\t') {
+			line = line[1:]
+		}
+
+		// A line containing a single full stop is an empty line separating paragraphs
+		if strings.TrimSpace(line) == "." || strings.TrimSpace(line) == "" {
+			current = nil
+			separated = true
+			continue
+		}
+
+		verbatim := line[0] == ' ' || line[0] == '\t'
+		if !verbatim {
+			line = strings.TrimRight(line, " \t")
+		}
+
+		// Consecutive lines of the same kind belong to the same paragraph
+		if current == nil || current.Verbatim != verbatim {
+			d.Paragraphs = append(d.Paragraphs, DescriptionParagraph{Verbatim: verbatim, adjacent: !separated})
+			current = &d.Paragraphs[len(d.Paragraphs)-1]
+			separated = false
+		}
+		current.Lines = append(current.Lines, line)
+	}
+
+	return d
+}
+
+// Text returns the content of the paragraph, with the lines of regular paragraphs
+// joined into a single line
+func (dp DescriptionParagraph) Text() string {
+	if dp.Verbatim {
+		return strings.Join(dp.Lines, "\n")
+	}
+	return strings.Join(dp.Lines, " ")
+}
+
+// Extended returns the extended description as plain text, without the synopsis
+func (d Description) Extended() string {
+	paragraphs := make([]string, len(d.Paragraphs))
+	for i, p := range d.Paragraphs {
+		paragraphs[i] = p.Text()
+	}
+	return strings.Join(paragraphs, "\n\n")
+}
+
+// PlainText returns the description as plain text, the synopsis followed by the
+// paragraphs of the extended description separated by blank lines
+func (d Description) PlainText() string {
+	if len(d.Paragraphs) == 0 {
+		return d.Synopsis
+	}
+	return d.Synopsis + "\n\n" + d.Extended()
+}
+
+// Markdown returns the description in Markdown, with the synopsis as a heading
+// and verbatim paragraphs as code blocks
+func (d Description) Markdown() string {
+	var sb strings.Builder
+	sb.WriteString("# ")
+	sb.WriteString(escapeMarkdown(d.Synopsis))
+	sb.WriteByte('\n')
+
+	for _, p := range d.Paragraphs {
+		sb.WriteByte('\n')
+		if p.Verbatim {
+			sb.WriteString("```\n")
+			sb.WriteString(p.Text())
+			sb.WriteString("\n```\n")
+			continue
+		}
+		sb.WriteString(escapeMarkdown(p.Text()))
+		sb.WriteByte('\n')
+	}
+
+	return sb.String()
+}
+
+// String returns the description in the format of the Description field
+func (d Description) String() string {
+	var sb strings.Builder
+	sb.WriteString(d.Synopsis)
+
+	for i, p := range d.Paragraphs {
+		if i > 0 && !p.adjacent {
+			sb.WriteString("\n .")
+		}
+		for _, line := range p.Lines {
+			sb.WriteString("\n ")
+			sb.WriteString(line)
+		}
+	}
+
+	return sb.String()
+}
+
+// Description returns the parsed Description field of the package
+func (dp *DebPackage) Description() Description {
+	return ParseDescription(dp.Get("Description"))
+}
+
+// DescriptionMD5 returns the MD5 digest of the Description field as computed by apt
+// for the Description-md5 field of Packages and Translation files
+func (dp *DebPackage) DescriptionMD5() string {
+	sum := md5.Sum([]byte(dp.Get("Description") + "\n"))
+	return hex.EncodeToString(sum[:])
+}
+
+// escapeMarkdown escapes the characters that have a meaning in Markdown
+func escapeMarkdown(text string) string {
+	var sb strings.Builder
+	for i, c := range text {
+		if strings.ContainsRune("\\`*_[]<>", c) || (i == 0 && strings.ContainsRune("#+-", c)) {
+			sb.WriteByte('\\')
+		}
+		sb.WriteRune(c)
+	}
+	return sb.String()
+}
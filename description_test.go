@@ -0,0 +1,96 @@
+package dpkg
+
+import (
+	"os"
+	"slices"
+	"testing"
+)
+
+// TestParseDescription tests the ParseDescription function
+func TestParseDescription(t *testing.T) {
+	file, err := os.Open("testdata/control/control-2")
+	if err != nil {
+		t.Fatalf("Failed to open control file: %v", err)
+	}
+	defer file.Close()
+
+	pkg, err := parseControlFile(file)
+	if err != nil {
+		t.Fatalf("Failed to parse control file: %v", err)
+	}
+
+	d := pkg.Description()
+	if d.Synopsis != "Vi IMproved - enhanced vi editor - compact version" {
+		t.Errorf("Synopsis = %q", d.Synopsis)
+	}
+	if len(d.Paragraphs) != 3 {
+		t.Fatalf("Expected 3 paragraphs, got %d", len(d.Paragraphs))
+	}
+	if got := d.Paragraphs[0].Text(); got != "Vim is an almost compatible version of the UNIX editor Vi." {
+		t.Errorf("First paragraph = %q", got)
+	}
+	if got := d.Paragraphs[2].Text(); got != "If a vim binary is wanted, try one of the following more featureful packages: vim, vim-nox, vim-motif, or vim-gtk3." {
+		t.Errorf("Last paragraph = %q", got)
+	}
+
+	// The description is serialized back as it was read
+	if got := d.String(); got != pkg.Get("Description") {
+		t.Errorf("String() = %q; want %q", got, pkg.Get("Description"))
+	}
+}
+
+// TestParseDescriptionVerbatim tests the handling of verbatim lines
+func TestParseDescriptionVerbatim(t *testing.T) {
+	value := "tool to do things\n Features:\n   * fast\n   * small\n .\n Use it like this:\n .\n   $ tool --help\n"
+
+	d := ParseDescription(value)
+	want := []DescriptionParagraph{
+		{Verbatim: false, Lines: []string{"Features:"}},
+		{Verbatim: true, Lines: []string{"  * fast", "  * small"}},
+		{Verbatim: false, Lines: []string{"Use it like this:"}},
+		{Verbatim: true, Lines: []string{"  $ tool --help"}},
+	}
+
+	if len(d.Paragraphs) != len(want) {
+		t.Fatalf("Expected %d paragraphs, got %d: %+v", len(want), len(d.Paragraphs), d.Paragraphs)
+	}
+	for i, p := range d.Paragraphs {
+		if p.Verbatim != want[i].Verbatim || !slices.Equal(p.Lines, want[i].Lines) {
+			t.Errorf("Paragraph %d = %+v; want %+v", i, p, want[i])
+		}
+	}
+
+	if got := d.String(); got != "tool to do things\n Features:\n   * fast\n   * small\n .\n Use it like this:\n .\n   $ tool --help" {
+		t.Errorf("String() = %q", got)
+	}
+
+	wantText := "tool to do things\n\nFeatures:\n\n  * fast\n  * small\n\nUse it like this:\n\n  $ tool --help"
+	if got := d.PlainText(); got != wantText {
+		t.Errorf("PlainText() = %q; want %q", got, wantText)
+	}
+
+	wantMarkdown := "# tool to do things\n\nFeatures:\n\n```\n  * fast\n  * small\n```\n\nUse it like this:\n\n```\n  $ tool --help\n```\n"
+	if got := d.Markdown(); got != wantMarkdown {
+		t.Errorf("Markdown() = %q; want %q", got, wantMarkdown)
+	}
+}
+
+// TestDescriptionMarkdownEscaping tests that Markdown characters are escaped in regular paragraphs
+func TestDescriptionMarkdownEscaping(t *testing.T) {
+	d := ParseDescription("library for *nix [C++]\n - uses <stdio.h>\n")
+
+	want := "# library for \\*nix \\[C++\\]\n\n\\- uses \\<stdio.h\\>\n"
+	if got := d.Markdown(); got != want {
+		t.Errorf("Markdown() = %q; want %q", got, want)
+	}
+}
+
+// TestDescriptionMD5 tests the DescriptionMD5 method of DebPackage
+func TestDescriptionMD5(t *testing.T) {
+	pkg := &DebPackage{}
+	pkg.Set("Description", "short\n line")
+
+	if got := pkg.DescriptionMD5(); got != "488438d85f4f6e83bb0450e1eeebf723" {
+		t.Errorf("DescriptionMD5() = %q; want %q", got, "488438d85f4f6e83bb0450e1eeebf723")
+	}
+}
@@ -1,13 +1,26 @@
 package dpkg
 
 import (
+	"iter"
 	"os"
 	"strings"
 )
 
 // Dpkg represents a Debian package manager
 type Dpkg struct {
+	// StatusFileLocation overrides the status file of the dpkg database when it is
+	// changed from DPKG_DATABASE, like the --status-file option of dpkg-scanpackages
 	StatusFileLocation string
+	// Root is the directory where the packages are installed, "/" when empty, like the
+	// --root option of dpkg. Symlinks inside it are resolved relative to it.
+	Root string
+	// AdminDir is the location of the dpkg database, DPKG_ADMINDIR below Root when empty,
+	// like the --admindir option of dpkg
+	AdminDir string
+	// Database selects the file of the dpkg database the packages are read from
+	Database Database
+	// Strict reports syntax errors in the dpkg database as *ParseError
+	Strict bool
 }
 
 // NewDpkg creates a new instance of Dpkg
@@ -28,20 +41,40 @@ func (d *Dpkg) Info(debFile string) (*DebPackage, error) {
 
 // List lists packages from the default dpkg database
 func (d *Dpkg) List() ([]DebPackage, error) {
-	return parseStatusFile(d.StatusFileLocation)
+	return d.ListFunc(func(pkg *DebPackage) bool { return true })
+}
+
+// Packages returns an iterator over the packages from the default dpkg database,
+// reading them one at a time so that large databases are processed with constant memory
+func (d *Dpkg) Packages() iter.Seq2[*DebPackage, error] {
+	return d.readDatabase()
 }
 
 // ListGrep lists packages from the default dpkg database that match the given package name
 func (d *Dpkg) ListGrep(pkgName string) ([]DebPackage, error) {
-	packages, err := parseStatusFile(d.StatusFileLocation)
-	if err != nil {
-		return nil, err
-	}
+	return d.ListFunc(func(pkg *DebPackage) bool {
+		return strings.Contains(pkg.Get("Package"), pkgName)
+	})
+}
+
+// ListInstalled lists packages from the default dpkg database that are fully installed,
+// leaving out removed packages whose configuration files remain and partial installations
+func (d *Dpkg) ListInstalled() ([]DebPackage, error) {
+	return d.ListFunc(func(pkg *DebPackage) bool {
+		status, err := pkg.Status()
+		return err == nil && status.IsInstalled()
+	})
+}
 
+// ListFunc lists packages from the default dpkg database for which keep returns true
+func (d *Dpkg) ListFunc(keep func(pkg *DebPackage) bool) ([]DebPackage, error) {
 	var filteredPackages []DebPackage
-	for _, pkg := range packages {
-		if strings.Contains(pkg.Fields["Package"], pkgName) {
-			filteredPackages = append(filteredPackages, pkg)
+	for pkg, err := range d.Packages() {
+		if err != nil {
+			return nil, err
+		}
+		if keep(pkg) {
+			filteredPackages = append(filteredPackages, *pkg)
 		}
 	}
 
@@ -57,14 +90,5 @@ func (d *Dpkg) IsDebFile(debFile string) bool {
 	}
 	defer file.Close()
 
-	magicValue := "!<arch>\ndebian-binary"
-	magic := make([]byte, len(magicValue))
-
-	_, err = file.Read(magic)
-	if err != nil {
-		return false
-	}
-
-	// Check the "magic value" for the ar format
-	return strings.HasPrefix(string(magic), magicValue)
+	return d.IsDeb(file)
 }
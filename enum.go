@@ -0,0 +1,20 @@
+package dpkg
+
+// enumName returns the name of the i-th value of an enumeration, or "" if it is out of
+// range
+func enumName(names []string, i int) string {
+	if i < 0 || i >= len(names) {
+		return ""
+	}
+	return names[i]
+}
+
+// enumIndex returns the value of an enumeration with the given name
+func enumIndex(names []string, name string) (int, bool) {
+	for i, n := range names {
+		if n == name {
+			return i, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,267 @@
+package dpkg
+
+import (
+	"archive/tar"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ExtractOptions represents the options used when extracting a package
+type ExtractOptions struct {
+	// PreserveOwnership applies the owner and group of the entries, only when running as root
+	PreserveOwnership bool
+}
+
+// Extract extracts the data archive of a Debian package into targetDir, like dpkg -x,
+// and returns the entries that were written. Entries with absolute paths, ".." components,
+// symlinks pointing outside of targetDir or paths going through a symlink are rejected
+// with ErrUnsafePath. Device files and FIFOs are skipped.
+func (d *Dpkg) Extract(debFile, targetDir string, opts ExtractOptions) ([]DataEntry, error) {
+	var entries []DataEntry
+
+	err := openDebFile(debFile, func(a *DebArchive) error {
+		var err error
+		entries, err = a.Extract(targetDir, opts)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// extractDataArchive extracts the entries of the uncompressed data archive into targetDir
+func extractDataArchive(uncompressedReader io.Reader, targetDir string, opts ExtractOptions) ([]DataEntry, error) {
+	if err := os.MkdirAll(targetDir, 0755); err != nil {
+		return nil, err
+	}
+
+	x := &extractor{
+		targetDir: targetDir,
+		chown:     opts.PreserveOwnership && os.Geteuid() == 0,
+	}
+
+	err := walkDataArchive(uncompressedReader, func(header *tar.Header, r io.Reader) error {
+		entry, err := newDataEntry(header)
+		if err != nil {
+			return err
+		}
+
+		written, err := x.extract(entry, r)
+		if err != nil {
+			return err
+		}
+		if written {
+			x.entries = append(x.entries, entry)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Directory permissions and times are applied last, so read-only directories
+	// can be filled and their times are not updated by the creation of their children
+	for _, dir := range slices.Backward(x.dirs) {
+		if err := x.applyMetadata(dir.dest, dir.entry); err != nil {
+			return nil, err
+		}
+	}
+
+	return x.entries, nil
+}
+
+// extractedDir represents a directory whose metadata is applied at the end of the extraction
+type extractedDir struct {
+	dest  string
+	entry DataEntry
+}
+
+// extractor holds the state of an extraction
+type extractor struct {
+	targetDir string
+	chown     bool
+	entries   []DataEntry
+	dirs      []extractedDir
+}
+
+// extract writes a single entry, and reports if it was written
+func (x *extractor) extract(entry DataEntry, r io.Reader) (bool, error) {
+	name, err := cleanArchivePath(entry.Path)
+	if err != nil {
+		return false, err
+	}
+
+	// The root of the archive is the target directory itself
+	if name == "." {
+		return false, nil
+	}
+
+	switch entry.Type {
+	case EntryCharDevice, EntryBlockDevice, EntryFIFO:
+		return false, nil
+	}
+
+	if err := x.makeParents(name); err != nil {
+		return false, err
+	}
+	dest := filepath.Join(x.targetDir, filepath.FromSlash(name))
+
+	if err := removeExisting(dest, entry.Type == EntryDirectory); err != nil {
+		return false, err
+	}
+	if entry.Type != EntryDirectory {
+		// A directory replaced by another entry no longer gets its metadata, which would
+		// otherwise be applied through a symlink
+		x.dirs = slices.DeleteFunc(x.dirs, func(dir extractedDir) bool { return dir.dest == dest })
+	}
+
+	switch entry.Type {
+	case EntryDirectory:
+		if err := os.Mkdir(dest, 0700); err != nil && !errors.Is(err, fs.ErrExist) {
+			return false, err
+		}
+		x.dirs = append(x.dirs, extractedDir{dest: dest, entry: entry})
+		return true, nil
+
+	case EntrySymlink:
+		if escapesRoot(name, entry.LinkTarget) {
+			return false, fmt.Errorf("%w: symlink %s points outside of the target directory: %s", ErrUnsafePath, entry.Path, entry.LinkTarget)
+		}
+		if err := os.Symlink(entry.LinkTarget, dest); err != nil {
+			return false, err
+		}
+		if x.chown {
+			if err := os.Lchown(dest, entry.UID, entry.GID); err != nil {
+				return false, err
+			}
+		}
+		return true, nil
+
+	case EntryHardlink:
+		target, err := cleanArchivePath(entry.LinkTarget)
+		if err != nil {
+			return false, err
+		}
+		if err := x.checkParents(target); err != nil {
+			return false, err
+		}
+		targetPath := filepath.Join(x.targetDir, filepath.FromSlash(target))
+		info, err := os.Lstat(targetPath)
+		if err != nil {
+			return false, fmt.Errorf("go-apt/dpkg: hardlink %s: %w", entry.Path, err)
+		}
+		if !info.Mode().IsRegular() {
+			return false, fmt.Errorf("%w: hardlink %s does not point to a regular file: %s", ErrUnsafePath, entry.Path, entry.LinkTarget)
+		}
+		if err := os.Link(targetPath, dest); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+
+	file, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return false, err
+	}
+	if _, err := io.Copy(file, r); err != nil {
+		file.Close()
+		return false, err
+	}
+	if err := file.Close(); err != nil {
+		return false, err
+	}
+
+	return true, x.applyMetadata(dest, entry)
+}
+
+// applyMetadata applies the ownership, permissions and modification time of an entry
+func (x *extractor) applyMetadata(dest string, entry DataEntry) error {
+	// Ownership must be changed first, as chown clears the setuid and setgid bits
+	if x.chown {
+		if err := os.Lchown(dest, entry.UID, entry.GID); err != nil {
+			return err
+		}
+	}
+	if err := os.Chmod(dest, entry.Mode); err != nil {
+		return err
+	}
+	return os.Chtimes(dest, time.Time{}, entry.ModTime)
+}
+
+// makeParents creates the missing parent directories of name, after checking that
+// none of the existing ones is a symlink
+func (x *extractor) makeParents(name string) error {
+	if err := x.checkParents(name); err != nil {
+		return err
+	}
+	return os.MkdirAll(filepath.Join(x.targetDir, filepath.FromSlash(path.Dir(name))), 0755)
+}
+
+// checkParents checks that the parent directories of name are not symlinks, which
+// could make a write land outside of the target directory
+func (x *extractor) checkParents(name string) error {
+	current := x.targetDir
+	parts := strings.Split(name, "/")
+	for _, part := range parts[:len(parts)-1] {
+		current = filepath.Join(current, part)
+		info, err := os.Lstat(current)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if info.Mode()&fs.ModeSymlink != 0 {
+			return fmt.Errorf("%w: %s goes through a symlink", ErrUnsafePath, name)
+		}
+	}
+	return nil
+}
+
+// removeExisting removes a file previously found at dest, so the new entry never writes
+// through an existing symlink; existing directories are kept when a directory is extracted
+func removeExisting(dest string, isDir bool) error {
+	info, err := os.Lstat(dest)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if isDir && info.IsDir() {
+		return nil
+	}
+	return os.Remove(dest)
+}
+
+// cleanArchivePath returns the cleaned path of an archive entry relative to the
+// target directory, rejecting absolute paths and ".." components
+func cleanArchivePath(name string) (string, error) {
+	if strings.HasPrefix(name, "/") {
+		return "", fmt.Errorf("%w: absolute path %s", ErrUnsafePath, name)
+	}
+	if slices.Contains(strings.Split(name, "/"), "..") {
+		return "", fmt.Errorf("%w: path traversal in %s", ErrUnsafePath, name)
+	}
+	return path.Clean(name), nil
+}
+
+// escapesRoot checks if a symlink at name with the given target resolves outside of the
+// target directory; absolute targets are resolved from the target directory, as in a chroot
+func escapesRoot(name, target string) bool {
+	if path.IsAbs(target) {
+		return false
+	}
+	resolved := path.Join(path.Dir(name), target)
+	return resolved == ".." || strings.HasPrefix(resolved, "../")
+}
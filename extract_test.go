@@ -0,0 +1,227 @@
+package dpkg
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// buildTar creates an uncompressed tar archive from the given headers, using the
+// Linkname of regular files as their content
+func buildTar(t *testing.T, headers []tar.Header) *bytes.Buffer {
+	t.Helper()
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, h := range headers {
+		var content []byte
+		if h.Typeflag == tar.TypeReg {
+			content = []byte(h.Linkname)
+			h.Linkname = ""
+			h.Size = int64(len(content))
+		}
+		if err := tw.WriteHeader(&h); err != nil {
+			t.Fatalf("Failed to write tar header %s: %v", h.Name, err)
+		}
+		if _, err := tw.Write(content); err != nil {
+			t.Fatalf("Failed to write tar content %s: %v", h.Name, err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("Failed to close tar writer: %v", err)
+	}
+	return &buf
+}
+
+// TestExtract tests the Extract method of Dpkg
+func TestExtract(t *testing.T) {
+	dir := t.TempDir()
+
+	d := Dpkg{}
+	entries, err := d.Extract("testdata/debs/vim-tiny_7.0-122+1etch5_amd64.deb", dir, ExtractOptions{})
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+	if len(entries) == 0 {
+		t.Fatalf("Extract returned no entries")
+	}
+
+	info, err := os.Stat(filepath.Join(dir, "usr/bin/vim.tiny"))
+	if err != nil {
+		t.Fatalf("Failed to stat extracted binary: %v", err)
+	}
+	if info.Size() != 1148160 || info.Mode().Perm() != 0755 {
+		t.Errorf("vim.tiny size = %d, mode = %v; want 1148160, -rwxr-xr-x", info.Size(), info.Mode())
+	}
+
+	target, err := os.Readlink(filepath.Join(dir, "usr/share/doc/vim-tiny"))
+	if err != nil || target != "vim-common" {
+		t.Errorf("Readlink(usr/share/doc/vim-tiny) = %q, %v; want vim-common", target, err)
+	}
+}
+
+// TestExtractDataArchive tests the extraction of modes, links and times
+func TestExtractDataArchive(t *testing.T) {
+	dir := t.TempDir()
+	modTime := time.Date(2025, 2, 16, 1, 43, 0, 0, time.UTC)
+
+	archive := buildTar(t, []tar.Header{
+		{Name: "./", Typeflag: tar.TypeDir, Mode: 0755, ModTime: modTime},
+		{Name: "./usr/bin/", Typeflag: tar.TypeDir, Mode: 0555, ModTime: modTime},
+		{Name: "./usr/bin/vim.tiny", Typeflag: tar.TypeReg, Mode: 0755, ModTime: modTime, Linkname: "binary"},
+		{Name: "./usr/bin/ex", Typeflag: tar.TypeLink, Linkname: "./usr/bin/vim.tiny"},
+		{Name: "./usr/bin/vi", Typeflag: tar.TypeSymlink, Mode: 0777, Linkname: "/etc/alternatives/vi"},
+		{Name: "./dev/null", Typeflag: tar.TypeChar, Mode: 0666, Devmajor: 1, Devminor: 3},
+	})
+
+	entries, err := extractDataArchive(archive, dir, ExtractOptions{})
+	if err != nil {
+		t.Fatalf("extractDataArchive failed: %v", err)
+	}
+	if len(entries) != 4 {
+		t.Errorf("Expected 4 written entries, got %d: %v", len(entries), entries)
+	}
+
+	binary := filepath.Join(dir, "usr/bin/vim.tiny")
+	content, err := os.ReadFile(binary)
+	if err != nil || string(content) != "binary" {
+		t.Errorf("ReadFile(vim.tiny) = %q, %v", content, err)
+	}
+
+	binInfo, err := os.Stat(filepath.Join(dir, "usr/bin"))
+	if err != nil {
+		t.Fatalf("Failed to stat usr/bin: %v", err)
+	}
+	if binInfo.Mode().Perm() != 0555 || !binInfo.ModTime().Equal(modTime) {
+		t.Errorf("usr/bin mode = %v, time = %v; want dr-xr-xr-x, %v", binInfo.Mode(), binInfo.ModTime(), modTime)
+	}
+	t.Cleanup(func() { os.Chmod(filepath.Join(dir, "usr/bin"), 0755) })
+
+	fileInfo, _ := os.Stat(binary)
+	linkInfo, err := os.Stat(filepath.Join(dir, "usr/bin/ex"))
+	if err != nil || !os.SameFile(fileInfo, linkInfo) {
+		t.Errorf("usr/bin/ex is not a hardlink to usr/bin/vim.tiny: %v", err)
+	}
+
+	if target, err := os.Readlink(filepath.Join(dir, "usr/bin/vi")); err != nil || target != "/etc/alternatives/vi" {
+		t.Errorf("Readlink(usr/bin/vi) = %q, %v", target, err)
+	}
+
+	if _, err := os.Lstat(filepath.Join(dir, "dev/null")); err == nil {
+		t.Errorf("Device files should not be extracted")
+	}
+}
+
+// TestExtractUnsafe tests that unsafe entries are rejected
+func TestExtractUnsafe(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers []tar.Header
+	}{
+		{"absolute path", []tar.Header{
+			{Name: "/etc/passwd", Typeflag: tar.TypeReg, Mode: 0644, Linkname: "root"},
+		}},
+		{"path traversal", []tar.Header{
+			{Name: "./usr/../../escape", Typeflag: tar.TypeReg, Mode: 0644, Linkname: "data"},
+		}},
+		{"symlink escape", []tar.Header{
+			{Name: "./usr/lib", Typeflag: tar.TypeSymlink, Linkname: "../../.."},
+		}},
+		{"write through symlink", []tar.Header{
+			{Name: "./etc", Typeflag: tar.TypeSymlink, Linkname: "/etc"},
+			{Name: "./etc/passwd", Typeflag: tar.TypeReg, Mode: 0644, Linkname: "root"},
+		}},
+		{"hardlink traversal", []tar.Header{
+			{Name: "./passwd", Typeflag: tar.TypeLink, Linkname: "../passwd"},
+		}},
+		{"hardlink through symlink", []tar.Header{
+			{Name: "./etc", Typeflag: tar.TypeSymlink, Linkname: "/etc"},
+			{Name: "./passwd", Typeflag: tar.TypeLink, Linkname: "./etc/passwd"},
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			root := t.TempDir()
+			dir := filepath.Join(root, "target")
+
+			_, err := extractDataArchive(buildTar(t, test.headers), dir, ExtractOptions{})
+			if !errors.Is(err, ErrUnsafePath) {
+				t.Errorf("extractDataArchive error = %v; want ErrUnsafePath", err)
+			}
+		})
+	}
+}
+
+// TestExtractReplacesSymlink tests that a file replacing a symlink does not write through it
+func TestExtractReplacesSymlink(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "target")
+	outside := filepath.Join(root, "outside")
+	if err := os.WriteFile(outside, []byte("original"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	archive := buildTar(t, []tar.Header{
+		{Name: "./config", Typeflag: tar.TypeSymlink, Linkname: "../outside"},
+	})
+	if _, err := extractDataArchive(archive, dir, ExtractOptions{}); !errors.Is(err, ErrUnsafePath) {
+		t.Fatalf("extractDataArchive error = %v; want ErrUnsafePath", err)
+	}
+
+	// A symlink left by a previous extraction is replaced, not followed
+	if err := os.Symlink(outside, filepath.Join(dir, "config")); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+	archive = buildTar(t, []tar.Header{
+		{Name: "./config", Typeflag: tar.TypeReg, Mode: 0644, Linkname: "new"},
+	})
+	if _, err := extractDataArchive(archive, dir, ExtractOptions{}); err != nil {
+		t.Fatalf("extractDataArchive failed: %v", err)
+	}
+
+	if content, _ := os.ReadFile(outside); string(content) != "original" {
+		t.Errorf("File outside of the target directory was modified: %q", content)
+	}
+	if content, _ := os.ReadFile(filepath.Join(dir, "config")); string(content) != "new" {
+		t.Errorf("config = %q; want new", content)
+	}
+}
+
+// TestExtractReplacedDirectory tests that the metadata of a directory replaced by a
+// symlink is not applied through the symlink
+func TestExtractReplacedDirectory(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "target")
+	outside := filepath.Join(root, "outside")
+	if err := os.WriteFile(outside, []byte("original"), 0600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	before, err := os.Stat(outside)
+	if err != nil {
+		t.Fatalf("Failed to stat file: %v", err)
+	}
+
+	archive := buildTar(t, []tar.Header{
+		{Name: "./a/", Typeflag: tar.TypeDir, Mode: 0777, ModTime: time.Unix(0, 0)},
+		{Name: "./a", Typeflag: tar.TypeSymlink, Linkname: outside},
+	})
+	if _, err := extractDataArchive(archive, dir, ExtractOptions{}); err != nil {
+		t.Fatalf("extractDataArchive failed: %v", err)
+	}
+
+	after, err := os.Stat(outside)
+	if err != nil {
+		t.Fatalf("Failed to stat file: %v", err)
+	}
+	if after.Mode() != before.Mode() || !after.ModTime().Equal(before.ModTime()) {
+		t.Errorf("File outside of the target directory changed to %v %v; was %v %v", after.Mode(), after.ModTime(), before.Mode(), before.ModTime())
+	}
+	if target, err := os.Readlink(filepath.Join(dir, "a")); err != nil || target != outside {
+		t.Errorf("Readlink(a) = %q, %v; want %q", target, err, outside)
+	}
+}
@@ -0,0 +1,192 @@
+package dpkg
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MultiArch represents the value of the Multi-Arch field
+// https://wiki.debian.org/Multiarch/Implementation
+type MultiArch int
+
+const (
+	MultiArchNo MultiArch = iota
+	MultiArchSame
+	MultiArchForeign
+	MultiArchAllowed
+)
+
+// String returns the Multi-Arch field value
+func (ma MultiArch) String() string {
+	switch ma {
+	case MultiArchSame:
+		return "same"
+	case MultiArchForeign:
+		return "foreign"
+	case MultiArchAllowed:
+		return "allowed"
+	}
+	return "no"
+}
+
+// Name returns the name of the package
+func (dp *DebPackage) Name() string {
+	return dp.Get("Package")
+}
+
+// Version returns the parsed version of the package
+func (dp *DebPackage) Version() (Version, error) {
+	return ParseVersion(dp.Get("Version"))
+}
+
+// Architecture returns the architecture of the package, e.g. "amd64" or "all"
+func (dp *DebPackage) Architecture() string {
+	return dp.Get("Architecture")
+}
+
+// InstalledSize returns the estimated installed size of the package in KiB,
+// or 0 if it is not known
+func (dp *DebPackage) InstalledSize() (int64, error) {
+	value, ok := dp.Lookup("Installed-Size")
+	if !ok || value == "" {
+		return 0, nil
+	}
+
+	size, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || size < 0 {
+		return 0, fmt.Errorf("%w: Installed-Size %q is not a valid size", ErrInvalidField, value)
+	}
+	return size, nil
+}
+
+// MultiArch returns the Multi-Arch value of the package, MultiArchNo when it is not set
+func (dp *DebPackage) MultiArch() (MultiArch, error) {
+	value := dp.Get("Multi-Arch")
+	switch strings.ToLower(value) {
+	case "", "no":
+		return MultiArchNo, nil
+	case "same":
+		return MultiArchSame, nil
+	case "foreign":
+		return MultiArchForeign, nil
+	case "allowed":
+		return MultiArchAllowed, nil
+	}
+	return MultiArchNo, fmt.Errorf("%w: unknown Multi-Arch value %q", ErrInvalidField, value)
+}
+
+// Essential checks if the package is marked as essential
+func (dp *DebPackage) Essential() bool {
+	return strings.EqualFold(dp.Get("Essential"), "yes")
+}
+
+// Priority returns the priority of the package, e.g. "required" or "optional"
+func (dp *DebPackage) Priority() string {
+	return dp.Get("Priority")
+}
+
+// Section returns the section of the package, e.g. "editors"
+func (dp *DebPackage) Section() string {
+	return dp.Get("Section")
+}
+
+// SourceName returns the name of the source package, which is the package name
+// itself when the Source field is absent
+func (dp *DebPackage) SourceName() string {
+	name, _ := dp.splitSource()
+	if name == "" {
+		return dp.Name()
+	}
+	return name
+}
+
+// SourceVersion returns the version of the source package, given in the Source field
+// as "src (version)" when it differs from the binary package version
+func (dp *DebPackage) SourceVersion() (Version, error) {
+	if _, version := dp.splitSource(); version != "" {
+		return ParseVersion(version)
+	}
+	return dp.Version()
+}
+
+// splitSource splits the Source field into the source package name and version
+func (dp *DebPackage) splitSource() (name, version string) {
+	source := strings.TrimSpace(dp.Get("Source"))
+	name, rest, found := strings.Cut(source, " ")
+	if !found {
+		return name, ""
+	}
+
+	rest = strings.TrimSpace(rest)
+	if strings.HasPrefix(rest, "(") && strings.HasSuffix(rest, ")") {
+		version = strings.TrimSpace(rest[1 : len(rest)-1])
+	}
+	return name, version
+}
+
+// Depends returns the parsed Depends field
+func (dp *DebPackage) Depends() (Relationships, error) {
+	return ParseRelationships(dp.Get("Depends"))
+}
+
+// PreDepends returns the parsed Pre-Depends field
+func (dp *DebPackage) PreDepends() (Relationships, error) {
+	return ParseRelationships(dp.Get("Pre-Depends"))
+}
+
+// Recommends returns the parsed Recommends field
+func (dp *DebPackage) Recommends() (Relationships, error) {
+	return ParseRelationships(dp.Get("Recommends"))
+}
+
+// Conflicts returns the parsed Conflicts field
+func (dp *DebPackage) Conflicts() (Relationships, error) {
+	return ParseRelationships(dp.Get("Conflicts"))
+}
+
+// Breaks returns the parsed Breaks field
+func (dp *DebPackage) Breaks() (Relationships, error) {
+	return ParseRelationships(dp.Get("Breaks"))
+}
+
+// Provides returns the parsed Provides field
+func (dp *DebPackage) Provides() (Relationships, error) {
+	return ParseRelationships(dp.Get("Provides"))
+}
+
+// Conffiles returns the parsed Conffiles field of the dpkg database, one conffile per
+// line with its digest, optionally followed by the obsolete and remove-on-upgrade flags
+func (dp *DebPackage) Conffiles() ([]Conffile, error) {
+	var conffiles []Conffile
+	for _, line := range strings.Split(dp.Get("Conffiles"), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		var conffile Conffile
+		for {
+			if rest, ok := strings.CutSuffix(line, " obsolete"); ok {
+				conffile.Obsolete = true
+				line = strings.TrimRight(rest, " ")
+			} else if rest, ok := strings.CutSuffix(line, " remove-on-upgrade"); ok {
+				conffile.RemoveOnUpgrade = true
+				line = strings.TrimRight(rest, " ")
+			} else {
+				break
+			}
+		}
+
+		// The path may contain spaces, the digest is the last word
+		i := strings.LastIndexByte(line, ' ')
+		if i < 0 || !strings.HasPrefix(line, "/") {
+			return nil, fmt.Errorf("%w: invalid Conffiles line %q", ErrInvalidField, line)
+		}
+		conffile.Path = strings.TrimRight(line[:i], " ")
+		conffile.Hash = line[i+1:]
+
+		conffiles = append(conffiles, conffile)
+	}
+	return conffiles, nil
+}
@@ -0,0 +1,153 @@
+package dpkg
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestDebPackageAccessors tests the typed accessors of DebPackage
+func TestDebPackageAccessors(t *testing.T) {
+	file, err := os.Open("testdata/control/control-2")
+	if err != nil {
+		t.Fatalf("Failed to open control file: %v", err)
+	}
+	defer file.Close()
+
+	pkg, err := parseControlFile(file)
+	if err != nil {
+		t.Fatalf("Failed to parse control file: %v", err)
+	}
+
+	if pkg.Name() != "vim-tiny" || pkg.Architecture() != "amd64" || pkg.Priority() != "important" || pkg.Section() != "editors" {
+		t.Errorf("Unexpected (Name, Architecture, Priority, Section) = (%q, %q, %q, %q)", pkg.Name(), pkg.Architecture(), pkg.Priority(), pkg.Section())
+	}
+
+	version, err := pkg.Version()
+	if err != nil || version.String() != "2:9.1.1113-1" {
+		t.Errorf("Version() = (%v, %v); want 2:9.1.1113-1", version, err)
+	}
+
+	size, err := pkg.InstalledSize()
+	if err != nil || size != 1818 {
+		t.Errorf("InstalledSize() = (%d, %v); want 1818", size, err)
+	}
+
+	if pkg.SourceName() != "vim" {
+		t.Errorf("SourceName() = %q; want vim", pkg.SourceName())
+	}
+	sourceVersion, err := pkg.SourceVersion()
+	if err != nil || sourceVersion.String() != "2:9.1.1113-1" {
+		t.Errorf("SourceVersion() = (%v, %v); want 2:9.1.1113-1", sourceVersion, err)
+	}
+
+	depends, err := pkg.Depends()
+	if err != nil || len(depends) != 5 || depends[2][0].Name != "libc6" {
+		t.Errorf("Depends() = (%v, %v)", depends, err)
+	}
+	provides, err := pkg.Provides()
+	if err != nil || provides.String() != "editor" {
+		t.Errorf("Provides() = (%v, %v); want editor", provides, err)
+	}
+
+	multiArch, err := pkg.MultiArch()
+	if err != nil || multiArch != MultiArchNo {
+		t.Errorf("MultiArch() = (%v, %v); want no", multiArch, err)
+	}
+	if pkg.Essential() {
+		t.Errorf("Essential() = true; want false")
+	}
+}
+
+// TestDebPackageSource tests the SourceName and SourceVersion methods of DebPackage
+func TestDebPackageSource(t *testing.T) {
+	tests := []struct {
+		content     string
+		wantName    string
+		wantVersion string
+	}{
+		{"Package: libfoo1\nVersion: 1.0-1+b2\n", "libfoo1", "1.0-1+b2"},
+		{"Package: libfoo1\nSource: foo\nVersion: 1.0-1\n", "foo", "1.0-1"},
+		{"Package: libfoo1\nSource: foo (1.0-1)\nVersion: 1.0-1+b2\n", "foo", "1.0-1"},
+	}
+
+	for _, tt := range tests {
+		pkg, err := parseControlFile(strings.NewReader(tt.content))
+		if err != nil {
+			t.Fatalf("Failed to parse control file: %v", err)
+		}
+
+		version, err := pkg.SourceVersion()
+		if err != nil {
+			t.Fatalf("SourceVersion() unexpected error: %v", err)
+		}
+		if pkg.SourceName() != tt.wantName || version.String() != tt.wantVersion {
+			t.Errorf("Source = (%q, %q); want (%q, %q)", pkg.SourceName(), version, tt.wantName, tt.wantVersion)
+		}
+	}
+}
+
+// TestDebPackageInvalidFields tests the errors reported by the typed accessors
+func TestDebPackageInvalidFields(t *testing.T) {
+	pkg := &DebPackage{}
+	pkg.Set("Package", "foo")
+
+	if size, err := pkg.InstalledSize(); err != nil || size != 0 {
+		t.Errorf("InstalledSize() = (%d, %v); want (0, nil) when absent", size, err)
+	}
+	if _, err := pkg.Version(); !errors.Is(err, ErrInvalidVersion) {
+		t.Errorf("Version() error = %v; want ErrInvalidVersion when absent", err)
+	}
+
+	pkg.Set("Installed-Size", "-1")
+	if _, err := pkg.InstalledSize(); !errors.Is(err, ErrInvalidField) {
+		t.Errorf("InstalledSize() error = %v; want ErrInvalidField", err)
+	}
+
+	pkg.Set("Multi-Arch", "sometimes")
+	if _, err := pkg.MultiArch(); !errors.Is(err, ErrInvalidField) {
+		t.Errorf("MultiArch() error = %v; want ErrInvalidField", err)
+	}
+
+	pkg.Set("Multi-Arch", "same")
+	pkg.Set("Essential", "yes")
+	if ma, err := pkg.MultiArch(); err != nil || ma != MultiArchSame || ma.String() != "same" {
+		t.Errorf("MultiArch() = (%v, %v); want same", ma, err)
+	}
+	if !pkg.Essential() {
+		t.Errorf("Essential() = false; want true")
+	}
+}
+
+// TestDebPackageConffiles tests the parsing of the Conffiles field of the dpkg database
+func TestDebPackageConffiles(t *testing.T) {
+	pkg := &DebPackage{}
+	if conffiles, err := pkg.Conffiles(); err != nil || conffiles != nil {
+		t.Errorf("Conffiles() = (%v, %v); want (nil, nil) when absent", conffiles, err)
+	}
+
+	pkg.Set("Conffiles", "\n /etc/foo.conf 0123456789abcdef0123456789abcdef\n"+
+		" /etc/foo bar.conf newconffile\n"+
+		" /etc/old.conf 0123456789abcdef0123456789abcdef obsolete\n"+
+		" /etc/gone.conf 0123456789abcdef0123456789abcdef obsolete remove-on-upgrade")
+	conffiles, err := pkg.Conffiles()
+	if err != nil {
+		t.Fatalf("Conffiles() failed: %v", err)
+	}
+	want := []Conffile{
+		{Path: "/etc/foo.conf", Hash: "0123456789abcdef0123456789abcdef"},
+		{Path: "/etc/foo bar.conf", Hash: "newconffile"},
+		{Path: "/etc/old.conf", Hash: "0123456789abcdef0123456789abcdef", Obsolete: true},
+		{Path: "/etc/gone.conf", Hash: "0123456789abcdef0123456789abcdef", Obsolete: true, RemoveOnUpgrade: true},
+	}
+	if !reflect.DeepEqual(conffiles, want) {
+		t.Errorf("Conffiles() = %+v; want %+v", conffiles, want)
+	}
+
+	pkg.Set("Conffiles", "\n etc/foo.conf 0123456789abcdef0123456789abcdef")
+	if _, err := pkg.Conffiles(); !errors.Is(err, ErrInvalidField) {
+		t.Errorf("Conffiles() error = %v; want ErrInvalidField", err)
+	}
+}
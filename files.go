@@ -0,0 +1,198 @@
+package dpkg
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// FileOwner represents an installed path and the packages whose file lists contain it
+type FileOwner struct {
+	Path     string
+	Packages []string
+}
+
+// Files returns the paths installed by a package, as listed in its info/<pkg>.list file.
+// The name can be qualified by an architecture, e.g. "libc6:amd64"; it must be for
+// Multi-Arch: same packages installed for several architectures.
+func (d *Dpkg) Files(pkgName string) ([]string, error) {
+	pkg, err := d.installedPackage(pkgName)
+	if err != nil {
+		return nil, err
+	}
+	return readListFile(d.adminPath("info", infoName(pkg)+".list"))
+}
+
+// installedPackage returns the stanza of the installed package
+func (d *Dpkg) installedPackage(pkgName string) (*DebPackage, error) {
+	name, arch, qualified := strings.Cut(pkgName, ":")
+
+	instances, err := d.ListFunc(func(pkg *DebPackage) bool {
+		if pkg.Name() != name || (qualified && pkg.Architecture() != arch) {
+			return false
+		}
+		status, err := pkg.Status()
+		return err == nil && status.State != StateNotInstalled
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	switch len(instances) {
+	case 0:
+		return nil, fmt.Errorf("%w: %s", ErrPackageNotInstalled, pkgName)
+	case 1:
+		return &instances[0], nil
+	default:
+		return nil, fmt.Errorf("%w: %s is installed for several architectures", ErrAmbiguousPackage, pkgName)
+	}
+}
+
+// infoName returns the name of the info files of an installed package, which is
+// qualified by the architecture for Multi-Arch: same packages
+func infoName(pkg *DebPackage) string {
+	if multiArch, _ := pkg.MultiArch(); multiArch == MultiArchSame {
+		return pkg.Name() + ":" + pkg.Architecture()
+	}
+	return pkg.Name()
+}
+
+// Owner returns the packages whose file lists contain the path, sorted by name
+func (d *Dpkg) Owner(filePath string) ([]string, error) {
+	owners, err := d.searchLists(func(name string) bool {
+		return name == path.Clean(filePath)
+	})
+	if err != nil || len(owners) == 0 {
+		return nil, err
+	}
+	return owners[0].Packages, nil
+}
+
+// Search returns the installed paths matching the pattern with the packages owning them,
+// sorted by path. Like dpkg -S, the pattern is a shell glob in which * also matches /,
+// an absolute path without wildcards must match exactly, and any other pattern without
+// wildcards matches paths containing it.
+func (d *Dpkg) Search(pattern string) ([]FileOwner, error) {
+	if !strings.ContainsAny(pattern, `*?[\`) {
+		if strings.HasPrefix(pattern, "/") {
+			owners, err := d.Owner(pattern)
+			if err != nil || len(owners) == 0 {
+				return nil, err
+			}
+			return []FileOwner{{Path: path.Clean(pattern), Packages: owners}}, nil
+		}
+		pattern = "*" + pattern + "*"
+	}
+
+	re, err := globRegexp(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return d.searchLists(re.MatchString)
+}
+
+// searchLists reads every file list of the info directory and returns the matching paths
+func (d *Dpkg) searchLists(match func(name string) bool) ([]FileOwner, error) {
+	entries, err := os.ReadDir(d.adminPath("info"))
+	if err != nil {
+		return nil, err
+	}
+
+	owners := make(map[string][]string)
+	for _, entry := range entries {
+		pkgName, ok := strings.CutSuffix(entry.Name(), ".list")
+		if !ok || entry.IsDir() {
+			continue
+		}
+
+		files, err := readListFile(d.adminPath("info", entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			// The root directory is listed as "/."
+			name := path.Clean(file)
+			if match(name) {
+				owners[name] = append(owners[name], pkgName)
+			}
+		}
+	}
+
+	var result []FileOwner
+	for name, packages := range owners {
+		result = append(result, FileOwner{Path: name, Packages: packages})
+	}
+	slices.SortFunc(result, func(a, b FileOwner) int { return strings.Compare(a.Path, b.Path) })
+	return result, nil
+}
+
+// readListFile reads a file list of the info directory, one path per line
+func readListFile(listFile string) ([]string, error) {
+	file, err := os.Open(listFile)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var files []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if line := scanner.Text(); line != "" {
+			files = append(files, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// globRegexp converts a shell glob to a regular expression matching whole paths, where
+// unlike path.Match the wildcards also match the separator, as with fnmatch(3) without
+// FNM_PATHNAME
+func globRegexp(pattern string) (*regexp.Regexp, error) {
+	var expr strings.Builder
+	expr.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '*':
+			expr.WriteString(".*")
+		case '?':
+			expr.WriteString(".")
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+			}
+			expr.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		case '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end == 0 && i+2 < len(pattern) {
+				// A leading ] is part of the class
+				end = 1 + strings.IndexByte(pattern[i+2:], ']')
+			}
+			if end <= 0 {
+				expr.WriteString(`\[`)
+				continue
+			}
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			expr.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		default:
+			expr.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+	expr.WriteString("$")
+
+	re, err := regexp.Compile(expr.String())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidPattern, pattern)
+	}
+	return re, nil
+}
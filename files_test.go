@@ -0,0 +1,161 @@
+package dpkg
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// newTestRoot copies the test root directory to a temporary directory and adds the file
+// list of the Multi-Arch: same libc6, whose name cannot be stored in a module
+func newTestRoot(t *testing.T) string {
+	t.Helper()
+
+	root := t.TempDir()
+	if err := os.CopyFS(root, os.DirFS("testdata/root")); err != nil {
+		t.Fatalf("Failed to copy the test root: %v", err)
+	}
+
+	libc := "/.\n/usr\n/usr/lib\n/usr/lib/x86_64-linux-gnu\n/usr/lib/x86_64-linux-gnu/libc.so.6\n" +
+		"/usr/share\n/usr/share/doc\n/usr/share/doc/libc6\n/usr/share/doc/libc6/copyright\n"
+	if err := os.WriteFile(filepath.Join(root, "var/lib/dpkg/info/libc6:amd64.list"), []byte(libc), 0644); err != nil {
+		t.Fatalf("Failed to write the libc6 file list: %v", err)
+	}
+	return root
+}
+
+// newTestAdminDir copies the test root directory and returns its status file
+func newTestAdminDir(t *testing.T) string {
+	t.Helper()
+	return filepath.Join(newTestRoot(t), "var/lib/dpkg/status")
+}
+
+// TestFiles tests the Files method of Dpkg
+func TestFiles(t *testing.T) {
+	d := Dpkg{StatusFileLocation: newTestAdminDir(t)}
+
+	tests := []struct {
+		pkgName string
+		want    []string
+		err     error
+	}{
+		{"nano", []string{"/.", "/etc", "/etc/nanorc"}, nil},
+		{"libc6", []string{"/.", "/usr", "/usr/lib", "/usr/lib/x86_64-linux-gnu", "/usr/lib/x86_64-linux-gnu/libc.so.6",
+			"/usr/share", "/usr/share/doc", "/usr/share/doc/libc6", "/usr/share/doc/libc6/copyright"}, nil},
+		{"libc6:amd64", []string{"/.", "/usr", "/usr/lib", "/usr/lib/x86_64-linux-gnu", "/usr/lib/x86_64-linux-gnu/libc.so.6",
+			"/usr/share", "/usr/share/doc", "/usr/share/doc/libc6", "/usr/share/doc/libc6/copyright"}, nil},
+		{"curl:amd64", []string{"/.", "/usr", "/usr/bin", "/usr/bin/curl", "/usr/share", "/usr/share/doc",
+			"/usr/share/doc/curl", "/usr/share/doc/curl/copyright"}, nil},
+		{"libc6:i386", nil, ErrPackageNotInstalled},
+		{"missing", nil, ErrPackageNotInstalled},
+	}
+
+	for _, test := range tests {
+		files, err := d.Files(test.pkgName)
+		if !errors.Is(err, test.err) {
+			t.Errorf("Files(%s) error = %v; want %v", test.pkgName, err, test.err)
+			continue
+		}
+		if !reflect.DeepEqual(files, test.want) {
+			t.Errorf("Files(%s) = %v; want %v", test.pkgName, files, test.want)
+		}
+	}
+}
+
+// TestFilesAmbiguous tests that Multi-Arch: same packages installed for several
+// architectures must be qualified
+func TestFilesAmbiguous(t *testing.T) {
+	statusFile := newTestAdminDir(t)
+	status, err := os.OpenFile(statusFile, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatalf("Failed to open %s: %v", statusFile, err)
+	}
+	_, err = status.WriteString("\nPackage: libc6\nStatus: install ok installed\nArchitecture: i386\nMulti-Arch: same\nVersion: 2.36-9+deb12u4\n")
+	if err := errors.Join(err, status.Close()); err != nil {
+		t.Fatalf("Failed to write %s: %v", statusFile, err)
+	}
+	list := filepath.Join(filepath.Dir(statusFile), "info", "libc6:i386.list")
+	if err := os.WriteFile(list, []byte("/.\n/usr\n/usr/lib\n/usr/lib/i386-linux-gnu\n/usr/lib/i386-linux-gnu/libc.so.6\n"), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", list, err)
+	}
+
+	d := Dpkg{StatusFileLocation: statusFile}
+	if _, err := d.Files("libc6"); !errors.Is(err, ErrAmbiguousPackage) {
+		t.Errorf("Files(libc6) error = %v; want ErrAmbiguousPackage", err)
+	}
+	files, err := d.Files("libc6:i386")
+	if err != nil || len(files) != 5 || files[4] != "/usr/lib/i386-linux-gnu/libc.so.6" {
+		t.Errorf("Files(libc6:i386) = %v, %v", files, err)
+	}
+	owners, err := d.Owner("/usr/lib")
+	if err != nil || !reflect.DeepEqual(owners, []string{"libc6:amd64", "libc6:i386"}) {
+		t.Errorf("Owner(/usr/lib) = %v, %v", owners, err)
+	}
+}
+
+// TestOwner tests the Owner method of Dpkg
+func TestOwner(t *testing.T) {
+	d := Dpkg{StatusFileLocation: newTestAdminDir(t)}
+
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{"/usr/bin/vim.tiny", []string{"vim-tiny"}},
+		{"/usr/lib/x86_64-linux-gnu/libc.so.6", []string{"libc6:amd64"}},
+		{"/usr/bin/", []string{"curl", "vim-tiny"}},
+		{"/", []string{"adduser", "curl", "libc6:amd64", "nano", "vim-tiny"}},
+		{"/usr/bin/missing", nil},
+	}
+
+	for _, test := range tests {
+		owners, err := d.Owner(test.path)
+		if err != nil {
+			t.Fatalf("Owner(%s) failed: %v", test.path, err)
+		}
+		if !reflect.DeepEqual(owners, test.want) {
+			t.Errorf("Owner(%s) = %v; want %v", test.path, owners, test.want)
+		}
+	}
+}
+
+// TestSearch tests the Search method of Dpkg
+func TestSearch(t *testing.T) {
+	d := Dpkg{StatusFileLocation: newTestAdminDir(t)}
+
+	tests := []struct {
+		pattern string
+		want    []FileOwner
+	}{
+		{"/usr/bin/curl", []FileOwner{{"/usr/bin/curl", []string{"curl"}}}},
+		{"/usr/bin/cur", nil},
+		{"vimrc", []FileOwner{{"/etc/vim/vimrc.tiny", []string{"vim-tiny"}}}},
+		{"/usr/*/copyright", []FileOwner{
+			{"/usr/share/doc/adduser/copyright", []string{"adduser"}},
+			{"/usr/share/doc/curl/copyright", []string{"curl"}},
+			{"/usr/share/doc/libc6/copyright", []string{"libc6:amd64"}},
+			{"/usr/share/doc/vim-tiny/copyright", []string{"vim-tiny"}},
+		}},
+		{"/usr/sbin/[!a]*", []FileOwner{{"/usr/sbin/deluser", []string{"adduser"}}}},
+		{"/etc/nano?c", []FileOwner{{"/etc/nanorc", []string{"nano"}}}},
+		{"*.so.[0-9]", []FileOwner{{"/usr/lib/x86_64-linux-gnu/libc.so.6", []string{"libc6:amd64"}}}},
+		{`/etc/nano\rc`, []FileOwner{{"/etc/nanorc", []string{"nano"}}}},
+		{"/usr/[bin", nil},
+	}
+
+	for _, test := range tests {
+		owners, err := d.Search(test.pattern)
+		if err != nil {
+			t.Fatalf("Search(%s) failed: %v", test.pattern, err)
+		}
+		if !reflect.DeepEqual(owners, test.want) {
+			t.Errorf("Search(%s) = %v; want %v", test.pattern, owners, test.want)
+		}
+	}
+
+	if _, err := d.Search("/usr/[z-a]"); !errors.Is(err, ErrInvalidPattern) {
+		t.Errorf("Search(/usr/[z-a]) error = %v; want ErrInvalidPattern", err)
+	}
+}
@@ -4,5 +4,6 @@ go 1.24.0
 
 require (
 	github.com/blakesmith/ar v0.0.0-20190502131153-809d4375e1fb
+	github.com/klauspost/compress v1.18.0
 	github.com/ulikunitz/xz v0.5.12
 )
@@ -2,76 +2,86 @@ package dpkg
 
 import (
 	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/bzip2"
 	"compress/gzip"
 	"fmt"
 	"io"
-	"os"
 	"path"
-	"strings"
 
-	"github.com/blakesmith/ar"
+	"github.com/klauspost/compress/zstd"
 	"github.com/ulikunitz/xz"
+	"github.com/ulikunitz/xz/lzma"
 )
 
 // readArchive reads the .deb file and extracts the control file
 func (d *Dpkg) readArchive(debFile string) (*DebPackage, error) {
-	file, err := os.Open(debFile)
+	var pkg *DebPackage
+
+	err := openDebFile(debFile, func(a *DebArchive) error {
+		var err error
+		pkg, err = a.Info()
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
-	defer file.Close()
-
-	reader := ar.NewReader(file)
 
-	for {
-		header, err := reader.Next()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return nil, err
-		}
+	pkg.Set("Filename", debFile)
+	return pkg, nil
+}
 
-		if strings.HasPrefix(header.Name, "control.tar") {
-			pkg, err := extractControlFile(header.Name, reader)
-			if err != nil {
-				return nil, err
-			}
-			pkg.Fields["Filename"] = debFile
-			return pkg, nil
-		}
+// extractControlFile extracts the control file from the archive
+func extractControlFile(filename string, arReader io.Reader) (*DebPackage, error) {
+	uncompressedData, err := decompressMember(filename, arReader)
+	if err != nil {
+		return nil, err
 	}
+	defer uncompressedData.Close()
 
-	return nil, ErrNoControlFile
+	return extractControlFromTarFile(uncompressedData)
 }
 
-// extractControlFile extracts the control file from the archive
-func extractControlFile(filename string, arReader io.Reader) (*DebPackage, error) {
-	var err error
-	var uncompressedData io.Reader
+// Magic bytes of the compression formats used by deb members
+var (
+	gzipMagic  = []byte{0x1f, 0x8b}
+	xzMagic    = []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}
+	zstdMagic  = []byte{0x28, 0xb5, 0x2f, 0xfd}
+	bzip2Magic = []byte{'B', 'Z', 'h'}
+)
 
-	// Handle different compression formats
-	// https://manpages.debian.org/buster/dpkg-dev/deb.5.en.html#FORMAT
-	switch path.Ext(filename) {
-	case ".tar":
-		uncompressedData = arReader
-	case ".gz":
-		uncompressedData, err = extractGzipFile(arReader)
-	case ".xz":
-		uncompressedData, err = extractXzFile(arReader)
-	default:
-		return nil, fmt.Errorf("go-apt/dpkg: unsupported compression format for %s", filename)
+// decompressMember returns the uncompressed content of a control.tar* or data.tar* member,
+// detecting the compression format from its magic bytes and falling back on the member
+// name extension for formats without magic bytes (plain tar and legacy lzma)
+// https://manpages.debian.org/bookworm/dpkg-dev/deb.5.en.html#FORMAT
+func decompressMember(filename string, arReader io.Reader) (io.ReadCloser, error) {
+	reader := bufio.NewReader(arReader)
+	magic, _ := reader.Peek(len(xzMagic))
+
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		return extractGzipFile(reader)
+	case bytes.HasPrefix(magic, xzMagic):
+		return extractXzFile(reader)
+	case bytes.HasPrefix(magic, zstdMagic):
+		return extractZstdFile(reader)
+	case bytes.HasPrefix(magic, bzip2Magic):
+		return extractBzip2File(reader)
 	}
 
-	if err != nil {
-		return nil, err
+	switch path.Ext(filename) {
+	case ".tar":
+		return io.NopCloser(reader), nil
+	case ".lzma":
+		return extractLzmaFile(reader)
 	}
 
-	return extractControlFromTarFile(uncompressedData)
+	return nil, fmt.Errorf("go-apt/dpkg: unsupported compression format for %s", filename)
 }
 
 // extractGzipFile extracts a gzip compressed file
-func extractGzipFile(compressedReader io.Reader) (io.Reader, error) {
+func extractGzipFile(compressedReader io.Reader) (io.ReadCloser, error) {
 	gzReader, err := gzip.NewReader(compressedReader)
 	if err != nil {
 		return nil, fmt.Errorf("go-apt/dpkg: failed to create gzip reader: %w", err)
@@ -80,12 +90,35 @@ func extractGzipFile(compressedReader io.Reader) (io.Reader, error) {
 }
 
 // extractXzFile extracts an xz compressed file
-func extractXzFile(compressedReader io.Reader) (io.Reader, error) {
+func extractXzFile(compressedReader io.Reader) (io.ReadCloser, error) {
 	xzReader, err := xz.NewReader(compressedReader)
 	if err != nil {
 		return nil, fmt.Errorf("go-apt/dpkg: failed to create xz reader: %w", err)
 	}
-	return xzReader, nil
+	return io.NopCloser(xzReader), nil
+}
+
+// extractZstdFile extracts a zstd compressed file
+func extractZstdFile(compressedReader io.Reader) (io.ReadCloser, error) {
+	zstdReader, err := zstd.NewReader(compressedReader, zstd.WithDecoderConcurrency(1))
+	if err != nil {
+		return nil, fmt.Errorf("go-apt/dpkg: failed to create zstd reader: %w", err)
+	}
+	return zstdReader.IOReadCloser(), nil
+}
+
+// extractBzip2File extracts a bzip2 compressed file
+func extractBzip2File(compressedReader io.Reader) (io.ReadCloser, error) {
+	return io.NopCloser(bzip2.NewReader(compressedReader)), nil
+}
+
+// extractLzmaFile extracts a legacy lzma compressed file
+func extractLzmaFile(compressedReader io.Reader) (io.ReadCloser, error) {
+	lzmaReader, err := lzma.NewReader(compressedReader)
+	if err != nil {
+		return nil, fmt.Errorf("go-apt/dpkg: failed to create lzma reader: %w", err)
+	}
+	return io.NopCloser(lzmaReader), nil
 }
 
 // extractControlFromTarFile extracts the control file from a tar archive
@@ -3,6 +3,7 @@ package dpkg
 import (
 	"fmt"
 	"os"
+	"strings"
 	"testing"
 )
 
@@ -10,30 +11,26 @@ import (
 func TestExtractControlFile(t *testing.T) {
 	tests := []struct {
 		filePath string
-		expected *DebPackage
+		expected map[string]string
 	}{
 		{
 			"testdata/debs/vim-tiny_9.1.1113-1_amd64.deb",
-			&DebPackage{
-				Fields: map[string]string{
-					"Package":      "vim-tiny",
-					"Version":      "2:9.1.1113-1",
-					"Architecture": "amd64",
-					"Maintainer":   "Debian Vim Maintainers <team+vim@tracker.debian.org>",
-					"Description":  "Vi IMproved - enhanced vi editor - compact version\n Vim is an almost compatible version of the UNIX editor Vi.\n .\n This package contains a minimal version of Vim compiled with no GUI and\n a small subset of features. This package's sole purpose is to provide\n the vi binary for base installations.\n .\n If a vim binary is wanted, try one of the following more featureful\n packages: vim, vim-nox, vim-motif, or vim-gtk3.",
-				},
+			map[string]string{
+				"Package":      "vim-tiny",
+				"Version":      "2:9.1.1113-1",
+				"Architecture": "amd64",
+				"Maintainer":   "Debian Vim Maintainers <team+vim@tracker.debian.org>",
+				"Description":  "Vi IMproved - enhanced vi editor - compact version\n Vim is an almost compatible version of the UNIX editor Vi.\n .\n This package contains a minimal version of Vim compiled with no GUI and\n a small subset of features. This package's sole purpose is to provide\n the vi binary for base installations.\n .\n If a vim binary is wanted, try one of the following more featureful\n packages: vim, vim-nox, vim-motif, or vim-gtk3.",
 			},
 		},
 		{
 			"testdata/debs/vim-tiny_7.0-122+1etch5_amd64.deb",
-			&DebPackage{
-				Fields: map[string]string{
-					"Package":      "vim-tiny",
-					"Version":      "1:7.0-122+1etch5",
-					"Architecture": "amd64",
-					"Maintainer":   "Debian VIM Maintainers <pkg-vim-maintainers@lists.alioth.debian.org>",
-					"Description":  "Vi IMproved - enhanced vi editor - compact version\n Vim is an almost compatible version of the UNIX editor Vi.\n .\n Many new features have been added: multi level undo, syntax\n highlighting, command line history, on-line help, filename\n completion, block operations, folding, Unicode support, etc.\n .\n This package contains a minimal version of vim compiled with no\n GUI and a small subset of features in order to keep small the\n package size. This package does not depend on the vim-runtime\n package, but installing it you will get its additional benefits\n (online documentation, plugins, ...).",
-				},
+			map[string]string{
+				"Package":      "vim-tiny",
+				"Version":      "1:7.0-122+1etch5",
+				"Architecture": "amd64",
+				"Maintainer":   "Debian VIM Maintainers <pkg-vim-maintainers@lists.alioth.debian.org>",
+				"Description":  "Vi IMproved - enhanced vi editor - compact version\n Vim is an almost compatible version of the UNIX editor Vi.\n .\n Many new features have been added: multi level undo, syntax\n highlighting, command line history, on-line help, filename\n completion, block operations, folding, Unicode support, etc.\n .\n This package contains a minimal version of vim compiled with no\n GUI and a small subset of features in order to keep small the\n package size. This package does not depend on the vim-runtime\n package, but installing it you will get its additional benefits\n (online documentation, plugins, ...).",
 			},
 		},
 	}
@@ -45,9 +42,9 @@ func TestExtractControlFile(t *testing.T) {
 			t.Fatalf("Failed to extract control file from %s: %v", test.filePath, err)
 		}
 
-		if pkg.Fields["Package"] != test.expected.Fields["Package"] || pkg.Fields["Version"] != test.expected.Fields["Version"] || pkg.Fields["Architecture"] != test.expected.Fields["Architecture"] || pkg.Fields["Maintainer"] != test.expected.Fields["Maintainer"] || pkg.Fields["Description"] != test.expected.Fields["Description"] {
-			fmt.Println(pkg.Fields["Description"])
-			fmt.Println(test.expected.Fields["Description"])
+		if pkg.Get("Package") != test.expected["Package"] || pkg.Get("Version") != test.expected["Version"] || pkg.Get("Architecture") != test.expected["Architecture"] || pkg.Get("Maintainer") != test.expected["Maintainer"] || pkg.Get("Description") != test.expected["Description"] {
+			fmt.Println(pkg.Get("Description"))
+			fmt.Println(test.expected["Description"])
 			t.Errorf("Extracted package metadata from %s does not match expected values", test.filePath)
 		}
 	}
@@ -82,3 +79,105 @@ func TestExtractXzFile(t *testing.T) {
 		t.Fatalf("Failed to extract xz file from %s: %v", filePath, err)
 	}
 }
+
+// TestExtractZstdFile tests the extractZstdFile function
+func TestExtractZstdFile(t *testing.T) {
+	filePath := "testdata/compressed/control.tar.zst"
+	file, err := os.Open(filePath)
+	if err != nil {
+		t.Fatalf("Failed to open file %s: %v", filePath, err)
+	}
+	defer file.Close()
+
+	r, err := extractZstdFile(file)
+	if err != nil {
+		t.Fatalf("Failed to extract zstd file from %s: %v", filePath, err)
+	}
+	r.Close()
+}
+
+// TestExtractBzip2File tests the extractBzip2File function
+func TestExtractBzip2File(t *testing.T) {
+	filePath := "testdata/compressed/control.tar.bz2"
+	file, err := os.Open(filePath)
+	if err != nil {
+		t.Fatalf("Failed to open file %s: %v", filePath, err)
+	}
+	defer file.Close()
+
+	_, err = extractBzip2File(file)
+	if err != nil {
+		t.Fatalf("Failed to extract bzip2 file from %s: %v", filePath, err)
+	}
+}
+
+// TestExtractLzmaFile tests the extractLzmaFile function
+func TestExtractLzmaFile(t *testing.T) {
+	filePath := "testdata/compressed/control.tar.lzma"
+	file, err := os.Open(filePath)
+	if err != nil {
+		t.Fatalf("Failed to open file %s: %v", filePath, err)
+	}
+	defer file.Close()
+
+	_, err = extractLzmaFile(file)
+	if err != nil {
+		t.Fatalf("Failed to extract lzma file from %s: %v", filePath, err)
+	}
+}
+
+// TestDecompressMember tests that the compression is detected from the magic bytes
+func TestDecompressMember(t *testing.T) {
+	tests := []struct {
+		filePath   string
+		memberName string
+	}{
+		{"testdata/compressed/control.tar.gz", "control.tar.gz"},
+		{"testdata/compressed/control.tar.xz", "control.tar.xz"},
+		{"testdata/compressed/control.tar.zst", "control.tar.zst"},
+		{"testdata/compressed/control.tar.bz2", "control.tar.bz2"},
+		{"testdata/compressed/control.tar.lzma", "control.tar.lzma"},
+		// Mismatched extensions are ignored when the format has magic bytes
+		{"testdata/compressed/control.tar.zst", "control.tar.gz"},
+		{"testdata/compressed/control.tar.xz", "control.tar"},
+		{"testdata/compressed/control.tar.gz", "data.tar"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.filePath+"_"+test.memberName, func(t *testing.T) {
+			file, err := os.Open(test.filePath)
+			if err != nil {
+				t.Fatalf("Failed to open file %s: %v", test.filePath, err)
+			}
+			defer file.Close()
+
+			pkg, err := extractControlFile(test.memberName, file)
+			if err != nil {
+				t.Fatalf("Failed to extract control file from %s: %v", test.filePath, err)
+			}
+			if pkg.Get("Package") != "vim-tiny" {
+				t.Errorf("Expected package vim-tiny, got %q", pkg.Get("Package"))
+			}
+		})
+	}
+
+	// Plain tar members are read as they are
+	file, err := os.Open("testdata/compressed/control.tar.gz")
+	if err != nil {
+		t.Fatalf("Failed to open file: %v", err)
+	}
+	defer file.Close()
+	gz, err := extractGzipFile(file)
+	if err != nil {
+		t.Fatalf("Failed to extract gzip file: %v", err)
+	}
+	pkg, err := extractControlFile("control.tar", gz)
+	if err != nil || pkg.Get("Package") != "vim-tiny" {
+		t.Errorf("Failed to extract control file from plain tar: %v", err)
+	}
+
+	// Unknown formats are rejected
+	if _, err := extractControlFile("control.tar.lz4", strings.NewReader("\x04\x22\x4d\x18")); err == nil {
+		t.Errorf("Expected an error for an unsupported compression format")
+	}
+}
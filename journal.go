@@ -0,0 +1,155 @@
+package dpkg
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"iter"
+	"os"
+	"slices"
+)
+
+// Database represents a file of the dpkg database read by the Dpkg methods
+type Database int
+
+const (
+	DatabaseStatus    Database = iota // The status file, with the pending updates applied
+	DatabaseStatusOld                 // The backup of the previous status file, status-old
+	DatabaseAvailable                 // The packages known to be available, available
+)
+
+var databaseNames = []string{"status", "status-old", "available"}
+
+// String returns the name of the file of the dpkg database
+func (db Database) String() string {
+	return enumName(databaseNames, int(db))
+}
+
+// ParseDatabase parses the name of a file of the dpkg database
+func ParseDatabase(s string) (Database, error) {
+	i, ok := enumIndex(databaseNames, s)
+	if !ok {
+		return 0, fmt.Errorf("go-apt/dpkg: unknown database file %q", s)
+	}
+	return Database(i), nil
+}
+
+// databaseFile returns the location of the file of the dpkg database to read
+func (d *Dpkg) databaseFile() string {
+	switch d.Database {
+	case DatabaseStatusOld:
+		return d.adminPath("status-old")
+	case DatabaseAvailable:
+		return d.adminPath("available")
+	}
+	return d.statusFile()
+}
+
+// readDatabase returns an iterator over the packages of the dpkg database. The status
+// file is streamed unless updates are pending in the journal, in which case it is loaded
+// to apply them.
+func (d *Dpkg) readDatabase() iter.Seq2[*DebPackage, error] {
+	if d.Database != DatabaseStatus {
+		return readStatusFile(d.databaseFile(), d.Strict)
+	}
+
+	return func(yield func(*DebPackage, error) bool) {
+		updates, err := d.pendingUpdates()
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		if len(updates) == 0 {
+			for pkg, err := range readStatusFile(d.statusFile(), d.Strict) {
+				if !yield(pkg, err) {
+					return
+				}
+			}
+			return
+		}
+
+		packages, err := parseStatusFile(d.statusFile(), d.Strict)
+		if err == nil {
+			packages, err = applyUpdates(packages, updates, d.Strict)
+		}
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		for i := range packages {
+			if !yield(&packages[i], nil) {
+				return
+			}
+		}
+	}
+}
+
+// pendingUpdates returns the files of the updates journal which dpkg has not folded into
+// the status file yet, in the order they must be applied
+func (d *Dpkg) pendingUpdates() ([]string, error) {
+	entries, err := os.ReadDir(d.adminPath("updates"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	// Only the names made of digits are updates, tmp.i is the one being written; the
+	// entries are sorted by name
+	var updates []string
+	for _, entry := range entries {
+		if !entry.IsDir() && isDigits(entry.Name()) {
+			updates = append(updates, d.adminPath("updates", entry.Name()))
+		}
+	}
+	return updates, nil
+}
+
+// applyUpdates applies the stanzas of the update files to the packages of the status
+// file, each stanza replacing the record of the package it describes
+func applyUpdates(packages []DebPackage, updates []string, strict bool) ([]DebPackage, error) {
+	for _, update := range updates {
+		for pkg, err := range readStatusFile(update, strict) {
+			if err != nil {
+				return nil, err
+			}
+			if i := findPackageSlot(packages, pkg); i >= 0 {
+				packages[i] = *pkg
+			} else {
+				packages = append(packages, *pkg)
+			}
+		}
+	}
+	return packages, nil
+}
+
+// findPackageSlot returns the index of the record replaced by pkg, or -1 for a new
+// package. Like dpkg, a package with a single installed instance is replaced whatever its
+// architecture, so that crossgrades are recorded, unless both are Multi-Arch: same; other
+// packages are matched by name and architecture.
+// https://salsa.debian.org/dpkg-team/dpkg/-/blob/main/lib/dpkg/parse.c
+func findPackageSlot(packages []DebPackage, pkg *DebPackage) int {
+	installed := -1
+	instances := 0
+	for i := range packages {
+		if packages[i].Name() != pkg.Name() {
+			continue
+		}
+		if status, err := packages[i].Status(); err == nil && status.State != StateNotInstalled {
+			installed = i
+			instances++
+		}
+	}
+
+	if instances == 1 {
+		existing, _ := packages[installed].MultiArch()
+		multiArch, _ := pkg.MultiArch()
+		if existing != MultiArchSame || multiArch != MultiArchSame {
+			return installed
+		}
+	}
+
+	return slices.IndexFunc(packages, func(p DebPackage) bool {
+		return p.Name() == pkg.Name() && p.Architecture() == pkg.Architecture()
+	})
+}
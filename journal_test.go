@@ -0,0 +1,105 @@
+package dpkg
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestDatabase tests reading the alternative files of the dpkg database
+func TestDatabase(t *testing.T) {
+	tests := []struct {
+		database Database
+		want     []string
+	}{
+		{DatabaseStatus, []string{"adduser", "libc6", "nano", "vim-tiny", "curl"}},
+		{DatabaseStatusOld, []string{"adduser", "libc6", "nano", "vim-tiny"}},
+		{DatabaseAvailable, []string{"curl", "htop"}},
+	}
+
+	for _, test := range tests {
+		d := Dpkg{Root: "testdata/root", Database: test.database}
+		packages, err := d.List()
+		if err != nil {
+			t.Fatalf("List() of %s failed: %v", test.database, err)
+		}
+		var names []string
+		for _, pkg := range packages {
+			names = append(names, pkg.Name())
+		}
+		if !reflect.DeepEqual(names, test.want) {
+			t.Errorf("List() of %s = %v; want %v", test.database, names, test.want)
+		}
+		if database, err := ParseDatabase(test.database.String()); err != nil || database != test.database {
+			t.Errorf("ParseDatabase(%s) = %v, %v", test.database, database, err)
+		}
+	}
+
+	if _, err := ParseDatabase("status-new"); err == nil {
+		t.Errorf("Expected an error for an unknown database file")
+	}
+}
+
+// TestPendingUpdates tests that the updates journal is applied to the status file
+func TestPendingUpdates(t *testing.T) {
+	root := newTestRoot(t)
+	updatesDir := filepath.Join(root, "var/lib/dpkg/updates")
+	if err := os.Mkdir(updatesDir, 0755); err != nil {
+		t.Fatalf("Failed to create updates directory: %v", err)
+	}
+
+	updates := map[string]string{
+		"0000":  "Package: curl\nStatus: install ok installed\nArchitecture: amd64\nVersion: 7.88.1-10+deb12u5\n",
+		"0001":  "Package: htop\nStatus: install ok unpacked\nArchitecture: amd64\nVersion: 3.2.2-2\n",
+		"0002":  "",
+		"0003":  "Package: libc6\nStatus: install ok unpacked\nArchitecture: i386\nMulti-Arch: same\nVersion: 2.36-9+deb12u4\n",
+		"0004":  "Package: vim-tiny\nStatus: install ok unpacked\nArchitecture: arm64\nVersion: 2:9.1.1113-1\n",
+		"0010":  "Package: curl\nStatus: install ok installed\nArchitecture: amd64\nVersion: 7.88.1-10+deb12u8\n",
+		"tmp.i": "Package: curl\nStatus: purge ok not-installed\nArchitecture: amd64\n",
+	}
+	for name, content := range updates {
+		if err := os.WriteFile(filepath.Join(updatesDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write update %s: %v", name, err)
+		}
+	}
+
+	d := NewDpkg()
+	d.Root = root
+	packages, err := d.List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+
+	var got []string
+	for _, pkg := range packages {
+		got = append(got, pkg.Name()+":"+pkg.Architecture()+" "+pkg.Get("Version")+" "+pkg.Get("Status"))
+	}
+	want := []string{
+		"adduser:all 3.134 install ok installed",
+		"libc6:amd64 2.36-9+deb12u4 install ok installed",
+		"nano:amd64 7.2-1 deinstall ok config-files",
+		"vim-tiny:arm64 2:9.1.1113-1 install ok unpacked",
+		"curl:amd64 7.88.1-10+deb12u8 install ok installed",
+		"htop:amd64 3.2.2-2 install ok unpacked",
+		"libc6:i386 2.36-9+deb12u4 install ok unpacked",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("List() =\n%v\nwant\n%v", got, want)
+	}
+
+	// The journal is not applied to the other files of the database
+	d.Database = DatabaseStatusOld
+	if packages, err := d.List(); err != nil || len(packages) != 4 {
+		t.Errorf("List() of status-old = %d packages, %v; want 4", len(packages), err)
+	}
+
+	d.Database = DatabaseStatus
+	d.Strict = true
+	if err := os.WriteFile(filepath.Join(updatesDir, "0011"), []byte("Package: curl\nbroken line\n"), 0644); err != nil {
+		t.Fatalf("Failed to write update: %v", err)
+	}
+	if _, err := d.List(); err == nil {
+		t.Errorf("Expected an error for an invalid update in strict mode")
+	}
+}
@@ -0,0 +1,51 @@
+package dpkg
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// DatabaseLock represents the locks of the dpkg database held by the process
+type DatabaseLock struct {
+	d     *Dpkg
+	files []*os.File
+}
+
+// Lock takes the lock-frontend and lock locks of the dpkg database, the fcntl(2) locks
+// taken by apt and dpkg, without waiting: ErrLocked is returned when another process holds
+// them. As fcntl locks belong to the process, closing any other descriptor of the lock
+// files in the same process releases them.
+func (d *Dpkg) Lock() (*DatabaseLock, error) {
+	l := &DatabaseLock{d: d}
+
+	// Frontends such as apt take lock-frontend first
+	for _, name := range []string{"lock-frontend", "lock"} {
+		file, err := os.OpenFile(d.adminPath(name), os.O_RDWR|os.O_CREATE, 0640)
+		if err != nil {
+			l.Unlock()
+			return nil, err
+		}
+		if err := lockFile(file); err != nil {
+			file.Close()
+			l.Unlock()
+			if errors.Is(err, ErrLocked) {
+				return nil, fmt.Errorf("%w: %s", err, file.Name())
+			}
+			return nil, err
+		}
+		l.files = append(l.files, file)
+	}
+
+	return l, nil
+}
+
+// Unlock releases the locks of the dpkg database
+func (l *DatabaseLock) Unlock() error {
+	var errs []error
+	for i := len(l.files) - 1; i >= 0; i-- {
+		errs = append(errs, l.files[i].Close())
+	}
+	l.files = nil
+	return errors.Join(errs...)
+}
@@ -0,0 +1,14 @@
+//go:build !unix
+
+package dpkg
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+)
+
+// lockFile reports that the fcntl(2) locks of dpkg are not available
+func lockFile(file *os.File) error {
+	return fmt.Errorf("go-apt/dpkg: locking the dpkg database is not supported on %s", runtime.GOOS)
+}
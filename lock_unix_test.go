@@ -0,0 +1,78 @@
+//go:build unix
+
+package dpkg
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"testing"
+)
+
+// TestLockHelper holds the lock of the dpkg database given in the environment until its
+// standard input is closed, for TestLock
+func TestLockHelper(t *testing.T) {
+	adminDir := os.Getenv("GO_DPKG_LOCK_ADMINDIR")
+	if adminDir == "" {
+		t.Skip("helper process of TestLock")
+	}
+
+	d := Dpkg{AdminDir: adminDir}
+	lock, err := d.Lock()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	fmt.Println("locked")
+	bufio.NewReader(os.Stdin).ReadString('\n')
+	lock.Unlock()
+	os.Exit(0)
+}
+
+// TestLock tests that the lock of the dpkg database excludes other processes
+func TestLock(t *testing.T) {
+	adminDir := t.TempDir()
+	d := Dpkg{AdminDir: adminDir}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestLockHelper$")
+	cmd.Env = append(os.Environ(), "GO_DPKG_LOCK_ADMINDIR="+adminDir)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		t.Fatalf("StdinPipe failed: %v", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatalf("StdoutPipe failed: %v", err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("Failed to start the helper process: %v", err)
+	}
+	defer cmd.Wait()
+	defer stdin.Close()
+
+	if line, err := bufio.NewReader(stdout).ReadString('\n'); err != nil || line != "locked\n" {
+		t.Fatalf("Helper process output %q, %v; want locked", line, err)
+	}
+
+	if _, err := d.Lock(); !errors.Is(err, ErrLocked) {
+		t.Errorf("Lock() error = %v; want ErrLocked", err)
+	}
+	if err := d.SetSelection("curl", WantHold); !errors.Is(err, ErrLocked) {
+		t.Errorf("SetSelection() error = %v; want ErrLocked", err)
+	}
+
+	stdin.Close()
+	if err := cmd.Wait(); err != nil {
+		t.Fatalf("Helper process failed: %v", err)
+	}
+
+	lock, err := d.Lock()
+	if err != nil {
+		t.Fatalf("Lock() after the helper exited failed: %v", err)
+	}
+	if err := lock.Unlock(); err != nil {
+		t.Errorf("Unlock failed: %v", err)
+	}
+}
@@ -0,0 +1,441 @@
+package dpkg
+
+import (
+	"bytes"
+	"encoding"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var (
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+	textMarshalerType   = reflect.TypeFor[encoding.TextMarshaler]()
+	paragraphType       = reflect.TypeFor[Paragraph]()
+	debPackageType      = reflect.TypeFor[DebPackage]()
+)
+
+// Unmarshal parses deb822 data and stores the result in the value pointed to by v,
+// which must be a pointer to a struct, a Paragraph or a DebPackage, or a pointer to a
+// slice of them to decode every paragraph
+//
+// Struct fields are matched case-insensitively against the field name given by the
+// "deb822" tag, or the Go field name when there is none, e.g.
+//
+//	type Package struct {
+//		Name          string        `deb822:"Package"`
+//		Version       Version       `deb822:"Version"`
+//		InstalledSize int64         `deb822:"Installed-Size"`
+//		Depends       Relationships `deb822:"Depends,omitempty"`
+//		Architectures []string      `deb822:"Architecture"`
+//		Binaries      []string      `deb822:"Binary,comma"`
+//		Conffiles     []string      `deb822:"Conffiles,lines"`
+//	}
+//
+// Supported field types are strings, integers, booleans ("yes" or "no"), slices of those
+// (separated by whitespace, commas with the "comma" option, or lines with the "lines" option),
+// pointers and types implementing encoding.TextUnmarshaler. A field of type Paragraph or
+// DebPackage receives the whole paragraph. Fields tagged with "-" are ignored.
+func Unmarshal(data []byte, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("go-apt/dpkg: Unmarshal requires a non-nil pointer, got %T", v)
+	}
+
+	dec := NewDecoder(bytes.NewReader(data))
+
+	// Decode every paragraph into a slice
+	if rv.Elem().Kind() == reflect.Slice {
+		slice := rv.Elem()
+		for {
+			elem := reflect.New(slice.Type().Elem())
+			err := dec.Decode(elem.Interface())
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			slice.Set(reflect.Append(slice, elem.Elem()))
+		}
+	}
+
+	if err := dec.Decode(v); err != nil {
+		if err == io.EOF {
+			return ErrNoParagraph
+		}
+		return err
+	}
+	return nil
+}
+
+// Marshal returns the deb822 encoding of v, which must be a struct, a Paragraph or a
+// DebPackage, a pointer to one of them, or a slice of them to encode several paragraphs
+// separated by blank lines; see Unmarshal for the supported struct fields, which are
+// written in their declaration order and can be skipped when empty with the "omitempty" option;
+// nil pointers are always skipped
+func Marshal(v any) ([]byte, error) {
+	var buffer bytes.Buffer
+	enc := NewEncoder(&buffer)
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer && !rv.IsNil() && rv.Elem().Kind() == reflect.Slice {
+		rv = rv.Elem()
+	}
+	if rv.Kind() == reflect.Slice {
+		for i := 0; i < rv.Len(); i++ {
+			if err := enc.Encode(rv.Index(i).Interface()); err != nil {
+				return nil, err
+			}
+		}
+		return buffer.Bytes(), nil
+	}
+
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// Decoder reads and decodes deb822 paragraphs from an input stream
+type Decoder struct {
+	// Strict enables the strict parsing mode, see ParagraphReader
+	Strict bool
+
+	r *ParagraphReader
+}
+
+// NewDecoder creates a new Decoder reading from r
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{
+		r: NewParagraphReader(r),
+	}
+}
+
+// Decode reads the next paragraph and stores it in the value pointed to by v,
+// returning io.EOF when there are no more paragraphs
+func (dec *Decoder) Decode(v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("go-apt/dpkg: Decode requires a non-nil pointer, got %T", v)
+	}
+
+	dec.r.Strict = dec.Strict
+	p, err := dec.r.Next()
+	if err != nil {
+		return err
+	}
+
+	return unmarshalParagraph(p, rv.Elem())
+}
+
+// Encoder writes deb822 encodings of values to an output stream
+type Encoder struct {
+	w *ParagraphWriter
+}
+
+// NewEncoder creates a new Encoder writing to w
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{
+		w: NewParagraphWriter(w),
+	}
+}
+
+// Encode writes the deb822 encoding of v as a new paragraph
+func (enc *Encoder) Encode(v any) error {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return fmt.Errorf("go-apt/dpkg: cannot marshal nil %T", v)
+		}
+		rv = rv.Elem()
+	}
+
+	p, err := marshalParagraph(rv)
+	if err != nil {
+		return err
+	}
+	return enc.w.Write(p)
+}
+
+// structField describes a struct field mapped to a deb822 field
+type structField struct {
+	index     int
+	name      string
+	omitEmpty bool
+	separator string // "comma", "lines" or "" for whitespace
+}
+
+// structFields returns the deb822 fields of a struct type
+func structFields(t reflect.Type) []structField {
+	var fields []structField
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		tag := sf.Tag.Get("deb822")
+		if tag == "-" {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = sf.Name
+		}
+
+		field := structField{index: i, name: name}
+		for _, opt := range strings.Split(opts, ",") {
+			switch opt {
+			case "omitempty":
+				field.omitEmpty = true
+			case "comma", "lines":
+				field.separator = opt
+			}
+		}
+		fields = append(fields, field)
+	}
+
+	return fields
+}
+
+// unmarshalParagraph stores the paragraph in the struct, Paragraph or DebPackage value
+func unmarshalParagraph(p *Paragraph, rv reflect.Value) error {
+	if rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		return unmarshalParagraph(p, rv.Elem())
+	}
+
+	switch rv.Type() {
+	case paragraphType:
+		rv.Set(reflect.ValueOf(*p))
+		return nil
+	case debPackageType:
+		rv.Set(reflect.ValueOf(DebPackage{Paragraph: *p}))
+		return nil
+	}
+
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("go-apt/dpkg: cannot unmarshal a paragraph into %s", rv.Type())
+	}
+
+	for _, field := range structFields(rv.Type()) {
+		fv := rv.Field(field.index)
+
+		// Paragraph and DebPackage fields receive the whole paragraph
+		if fv.Type() == paragraphType || fv.Type() == debPackageType {
+			if err := unmarshalParagraph(p, fv); err != nil {
+				return err
+			}
+			continue
+		}
+
+		value, ok := p.Lookup(field.name)
+		if !ok {
+			continue
+		}
+
+		if err := unmarshalValue(value, fv, field.separator); err != nil {
+			return fmt.Errorf("go-apt/dpkg: cannot unmarshal field %q into %s: %w", field.name, fv.Type(), err)
+		}
+	}
+
+	return nil
+}
+
+// unmarshalValue parses the field value into the Go value
+func unmarshalValue(value string, fv reflect.Value, separator string) error {
+	if fv.Kind() == reflect.Pointer {
+		if fv.IsNil() {
+			fv.Set(reflect.New(fv.Type().Elem()))
+		}
+		return unmarshalValue(value, fv.Elem(), separator)
+	}
+
+	if reflect.PointerTo(fv.Type()).Implements(textUnmarshalerType) {
+		return fv.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value))
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(value)
+	case reflect.Bool:
+		b, err := parseBool(value)
+		if err != nil {
+			return err
+		}
+		fv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(strings.TrimSpace(value), 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(strings.TrimSpace(value), 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetUint(n)
+	case reflect.Slice:
+		items := splitValue(value, separator)
+		slice := reflect.MakeSlice(fv.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := unmarshalValue(item, slice.Index(i), ""); err != nil {
+				return err
+			}
+		}
+		fv.Set(slice)
+	default:
+		return fmt.Errorf("unsupported type %s", fv.Type())
+	}
+
+	return nil
+}
+
+// marshalParagraph builds a paragraph from the struct, Paragraph or DebPackage value
+func marshalParagraph(rv reflect.Value) (*Paragraph, error) {
+	switch rv.Type() {
+	case paragraphType:
+		p := rv.Interface().(Paragraph)
+		return &p, nil
+	case debPackageType:
+		pkg := rv.Interface().(DebPackage)
+		return &pkg.Paragraph, nil
+	}
+
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("go-apt/dpkg: cannot marshal %s as a paragraph", rv.Type())
+	}
+
+	p := &Paragraph{}
+	var extra *Paragraph
+
+	for _, field := range structFields(rv.Type()) {
+		fv := rv.Field(field.index)
+
+		// Paragraph and DebPackage fields provide the remaining fields
+		if fv.Type() == paragraphType || fv.Type() == debPackageType {
+			extra, _ = marshalParagraph(fv)
+			continue
+		}
+
+		// Nil pointers mean that the field is absent
+		if (field.omitEmpty && fv.IsZero()) || (fv.Kind() == reflect.Pointer && fv.IsNil()) {
+			continue
+		}
+
+		value, err := marshalValue(fv, field.separator)
+		if err != nil {
+			return nil, fmt.Errorf("go-apt/dpkg: cannot marshal field %q from %s: %w", field.name, fv.Type(), err)
+		}
+		p.Set(field.name, value)
+	}
+
+	// Keep the fields of an embedded paragraph that are not mapped to a struct field
+	if extra != nil {
+		for _, f := range extra.fields {
+			if !p.Has(f.Name) {
+				p.fields = append(p.fields, f)
+			}
+		}
+	}
+
+	return p, nil
+}
+
+// marshalValue formats the Go value as a field value
+func marshalValue(fv reflect.Value, separator string) (string, error) {
+	if fv.Kind() == reflect.Pointer {
+		if fv.IsNil() {
+			return "", nil
+		}
+		return marshalValue(fv.Elem(), separator)
+	}
+
+	if fv.Type().Implements(textMarshalerType) {
+		text, err := fv.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(text), err
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		return fv.String(), nil
+	case reflect.Bool:
+		if fv.Bool() {
+			return "yes", nil
+		}
+		return "no", nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(fv.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(fv.Uint(), 10), nil
+	case reflect.Slice:
+		items := make([]string, fv.Len())
+		for i := range items {
+			item, err := marshalValue(fv.Index(i), "")
+			if err != nil {
+				return "", err
+			}
+			items[i] = item
+		}
+		return joinValue(items, separator), nil
+	}
+
+	return "", fmt.Errorf("unsupported type %s", fv.Type())
+}
+
+// splitValue splits a field value into the items of a list
+func splitValue(value, separator string) []string {
+	var items []string
+
+	switch separator {
+	case "comma":
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+	case "lines":
+		for _, item := range strings.Split(value, "\n") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+	default:
+		items = strings.Fields(value)
+	}
+
+	return items
+}
+
+// joinValue joins the items of a list into a field value
+func joinValue(items []string, separator string) string {
+	switch separator {
+	case "comma":
+		return strings.Join(items, ", ")
+	case "lines":
+		if len(items) == 0 {
+			return ""
+		}
+		return "\n " + strings.Join(items, "\n ")
+	}
+	return strings.Join(items, " ")
+}
+
+// parseBool parses the boolean values used in control files, such as "yes" and "no"
+func parseBool(value string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "yes", "true":
+		return true, nil
+	case "no", "false", "":
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid boolean value %q", value)
+}
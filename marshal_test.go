@@ -0,0 +1,221 @@
+package dpkg
+
+import (
+	"errors"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// testPackage is a struct used to test the deb822 encoding
+type testPackage struct {
+	Name          string        `deb822:"Package"`
+	Version       Version       `deb822:"Version"`
+	InstalledSize int64         `deb822:"Installed-Size"`
+	Essential     bool          `deb822:"Essential,omitempty"`
+	Depends       Relationships `deb822:"Depends,omitempty"`
+	Provides      *Relationships
+	Architectures []string `deb822:"Architecture"`
+	Binaries      []string `deb822:"Binary,comma,omitempty"`
+	Conffiles     []string `deb822:"Conffiles,lines,omitempty"`
+	Ignored       string   `deb822:"-"`
+}
+
+// TestUnmarshal tests the Unmarshal function
+func TestUnmarshal(t *testing.T) {
+	content, err := os.ReadFile("testdata/control/control-2")
+	if err != nil {
+		t.Fatalf("Failed to read control file: %v", err)
+	}
+
+	var pkg testPackage
+	if err := Unmarshal(content, &pkg); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	if pkg.Name != "vim-tiny" || pkg.Version.String() != "2:9.1.1113-1" || pkg.InstalledSize != 1818 {
+		t.Errorf("Unmarshal = (%q, %q, %d); want (vim-tiny, 2:9.1.1113-1, 1818)", pkg.Name, pkg.Version, pkg.InstalledSize)
+	}
+	if len(pkg.Depends) != 5 || pkg.Depends[0][0].Name != "vim-common" {
+		t.Errorf("Unexpected Depends %v", pkg.Depends)
+	}
+	if pkg.Provides == nil || pkg.Provides.String() != "editor" {
+		t.Errorf("Unexpected Provides %v", pkg.Provides)
+	}
+	if !slices.Equal(pkg.Architectures, []string{"amd64"}) {
+		t.Errorf("Unexpected Architectures %v", pkg.Architectures)
+	}
+}
+
+// TestUnmarshalTypes tests the supported field types and options
+func TestUnmarshalTypes(t *testing.T) {
+	content := "Package: foo\nVersion: 1.0\nInstalled-Size: 12\nEssential: yes\nArchitecture: amd64 i386\nBinary: foo, foo-dev,\n libfoo1\nConffiles:\n /etc/foo.conf 0123\n /etc/bar.conf 4567\nIgnored: value\n"
+
+	var pkg testPackage
+	if err := Unmarshal([]byte(content), &pkg); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	if !pkg.Essential {
+		t.Errorf("Expected Essential to be true")
+	}
+	if !slices.Equal(pkg.Architectures, []string{"amd64", "i386"}) {
+		t.Errorf("Unexpected Architectures %v", pkg.Architectures)
+	}
+	if !slices.Equal(pkg.Binaries, []string{"foo", "foo-dev", "libfoo1"}) {
+		t.Errorf("Unexpected Binaries %v", pkg.Binaries)
+	}
+	if !slices.Equal(pkg.Conffiles, []string{"/etc/foo.conf 0123", "/etc/bar.conf 4567"}) {
+		t.Errorf("Unexpected Conffiles %v", pkg.Conffiles)
+	}
+	if pkg.Ignored != "" {
+		t.Errorf("Expected Ignored to be skipped, got %q", pkg.Ignored)
+	}
+}
+
+// TestUnmarshalErrors tests the errors reported by Unmarshal
+func TestUnmarshalErrors(t *testing.T) {
+	var pkg testPackage
+
+	if err := Unmarshal([]byte("Package: foo\n"), pkg); err == nil {
+		t.Errorf("Expected an error for a non-pointer value")
+	}
+	if err := Unmarshal([]byte("\n\n"), &pkg); !errors.Is(err, ErrNoParagraph) {
+		t.Errorf("Expected ErrNoParagraph, got %v", err)
+	}
+	if err := Unmarshal([]byte("Package: foo\nVersion: a.b\n"), &pkg); !errors.Is(err, ErrInvalidVersion) {
+		t.Errorf("Expected ErrInvalidVersion, got %v", err)
+	}
+	if err := Unmarshal([]byte("Package: foo\nInstalled-Size: big\n"), &pkg); err == nil || !strings.Contains(err.Error(), "Installed-Size") {
+		t.Errorf("Expected an error mentioning Installed-Size, got %v", err)
+	}
+	if err := Unmarshal([]byte("Package: foo\nEssential: maybe\n"), &pkg); err == nil {
+		t.Errorf("Expected an error for an invalid boolean")
+	}
+}
+
+// TestUnmarshalSlice tests decoding every paragraph into a slice
+func TestUnmarshalSlice(t *testing.T) {
+	content, err := os.ReadFile("testdata/status")
+	if err != nil {
+		t.Fatalf("Failed to read status file: %v", err)
+	}
+
+	var pkgs []*testPackage
+	if err := Unmarshal(content, &pkgs); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	var names []string
+	for _, pkg := range pkgs {
+		names = append(names, pkg.Name)
+	}
+	if want := []string{"adduser", "apparmor", "apt", "apt-listchanges", "apt-mirror"}; !slices.Equal(names, want) {
+		t.Errorf("Unmarshal = %v; want %v", names, want)
+	}
+
+	var paragraphs []Paragraph
+	if err := Unmarshal(content, &paragraphs); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if len(paragraphs) != 5 || paragraphs[4].Get("Version") != "0.5.4-2" {
+		t.Errorf("Unexpected paragraphs %v", paragraphs)
+	}
+}
+
+// TestMarshal tests the Marshal function
+func TestMarshal(t *testing.T) {
+	version, _ := ParseVersion("1:2.0-1")
+	depends, _ := ParseRelationships("libc6 (>= 2.34), foo | bar")
+
+	pkgs := []testPackage{
+		{
+			Name:          "foo",
+			Version:       version,
+			InstalledSize: 42,
+			Essential:     true,
+			Depends:       depends,
+			Architectures: []string{"amd64", "i386"},
+			Binaries:      []string{"foo", "foo-dev"},
+			Conffiles:     []string{"/etc/foo.conf 0123"},
+			Ignored:       "ignored",
+		},
+		{Name: "bar", Version: version},
+	}
+
+	data, err := Marshal(pkgs)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	want := "Package: foo\nVersion: 1:2.0-1\nInstalled-Size: 42\nEssential: yes\nDepends: libc6 (>= 2.34), foo | bar\nArchitecture: amd64 i386\nBinary: foo, foo-dev\nConffiles:\n /etc/foo.conf 0123\n" +
+		"\nPackage: bar\nVersion: 1:2.0-1\nInstalled-Size: 0\nArchitecture:\n"
+	if string(data) != want {
+		t.Errorf("Marshal = %q; want %q", data, want)
+	}
+
+	// Marshaled data can be decoded back
+	var decoded []testPackage
+	if err := Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if len(decoded) != 2 || decoded[0].Depends.String() != depends.String() || !slices.Equal(decoded[0].Conffiles, pkgs[0].Conffiles) {
+		t.Errorf("Unexpected decoded packages %+v", decoded)
+	}
+}
+
+// TestMarshalParagraphField tests that a Paragraph field keeps the unmapped fields
+func TestMarshalParagraphField(t *testing.T) {
+	type withParagraph struct {
+		Name  string `deb822:"Package"`
+		Extra Paragraph
+	}
+
+	var v withParagraph
+	if err := Unmarshal([]byte("Package: foo\nSection: misc\nPriority: optional\n"), &v); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if v.Extra.Get("Section") != "misc" {
+		t.Errorf("Expected the Paragraph field to contain Section")
+	}
+
+	v.Name = "bar"
+	data, err := Marshal(&v)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if want := "Package: bar\nSection: misc\nPriority: optional\n"; string(data) != want {
+		t.Errorf("Marshal = %q; want %q", data, want)
+	}
+}
+
+// TestDecoder tests the streaming Decoder
+func TestDecoder(t *testing.T) {
+	file, err := os.Open("testdata/status")
+	if err != nil {
+		t.Fatalf("Failed to open status file: %v", err)
+	}
+	defer file.Close()
+
+	dec := NewDecoder(file)
+	dec.Strict = true
+
+	count := 0
+	for {
+		var pkg DebPackage
+		err := dec.Decode(&pkg)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Decode failed: %v", err)
+		}
+		count++
+	}
+
+	if count != 5 {
+		t.Errorf("Decoded %d packages; want 5", count)
+	}
+}
@@ -15,7 +15,7 @@ import (
 // DebPackage represents the metadata of a Debian package
 // https://salsa.debian.org/dpkg-team/dpkg/-/blob/main/lib/dpkg/parse.c?ref_type=heads#L53
 type DebPackage struct {
-	Fields map[string]string
+	Paragraph
 }
 
 // readDebFile opens the .deb file associated with the DebPackage
@@ -23,17 +23,17 @@ func (dp *DebPackage) readDebFile() (io.ReadCloser, error) {
 	if !dp.HasFilename() {
 		return nil, ErrNoFilenameAvailable
 	}
-	return os.Open(dp.Fields["Filename"])
+	return os.Open(dp.Get("Filename"))
 }
 
 // HasFilename checks if the DebPackage has a filename
 func (dp *DebPackage) HasFilename() bool {
-	return dp.Fields["Filename"] != ""
+	return dp.Get("Filename") != ""
 }
 
 // ShortDescription returns the short description of the package
 func (dp *DebPackage) ShortDescription() string {
-	return strings.Split(dp.Fields["Description"], "\n")[0]
+	return strings.Split(dp.Get("Description"), "\n")[0]
 }
 
 // CalculateAllHashes calculates the MD5, SHA1, and SHA256 hashes of the package content
@@ -44,25 +44,48 @@ func (dp *DebPackage) CalculateAllHashes() error {
 	}
 	defer r.Close()
 
-	// Create hashers
-	MD5 := md5.New()
-	SHA1 := sha1.New()
-	SHA256 := sha256.New()
-
-	// Use a MultiWriter to calculate all hashes simultaneously
-	multiWriter := io.MultiWriter(MD5, SHA1, SHA256)
-	if _, err := io.Copy(multiWriter, r); err != nil {
+	// Calculate all hashes simultaneously
+	hasher := newPackageHasher()
+	if _, err := io.Copy(hasher, r); err != nil {
 		return err
 	}
 
 	// Update hash fields
-	dp.Fields["MD5"] = hex.EncodeToString(MD5.Sum(nil))
-	dp.Fields["SHA1"] = hex.EncodeToString(SHA1.Sum(nil))
-	dp.Fields["SHA256"] = hex.EncodeToString(SHA256.Sum(nil))
+	hasher.apply(dp)
 
 	return nil
 }
 
+// packageHasher calculates the size and the MD5, SHA1 and SHA256 hashes of a package
+// in a single pass
+type packageHasher struct {
+	size   int64
+	md5    hash.Hash
+	sha1   hash.Hash
+	sha256 hash.Hash
+}
+
+// newPackageHasher creates a new packageHasher
+func newPackageHasher() *packageHasher {
+	return &packageHasher{md5: md5.New(), sha1: sha1.New(), sha256: sha256.New()}
+}
+
+// Write adds p to the size and the hashes
+func (h *packageHasher) Write(p []byte) (int, error) {
+	h.size += int64(len(p))
+	h.md5.Write(p)
+	h.sha1.Write(p)
+	h.sha256.Write(p)
+	return len(p), nil
+}
+
+// apply sets the hash fields of the package
+func (h *packageHasher) apply(dp *DebPackage) {
+	dp.Set("MD5", hex.EncodeToString(h.md5.Sum(nil)))
+	dp.Set("SHA1", hex.EncodeToString(h.sha1.Sum(nil)))
+	dp.Set("SHA256", hex.EncodeToString(h.sha256.Sum(nil)))
+}
+
 // calculateSingleHash calculates a single hash for the package content
 func (dp *DebPackage) calculateSingleHash(hashFunc func() hash.Hash, hashField string) error {
 	r, err := dp.readDebFile()
@@ -76,7 +99,7 @@ func (dp *DebPackage) calculateSingleHash(hashFunc func() hash.Hash, hashField s
 		return err
 	}
 
-	dp.Fields[hashField] = hex.EncodeToString(hash.Sum(nil))
+	dp.Set(hashField, hex.EncodeToString(hash.Sum(nil)))
 	return nil
 }
 
@@ -85,7 +108,7 @@ func (dp *DebPackage) MD5sum() string {
 	if err := dp.calculateSingleHash(md5.New, "MD5"); err != nil {
 		return ""
 	}
-	return dp.Fields["MD5"]
+	return dp.Get("MD5")
 }
 
 // SHA1sum returns the SHA1 hash of the package content
@@ -93,7 +116,7 @@ func (dp *DebPackage) SHA1sum() string {
 	if err := dp.calculateSingleHash(sha1.New, "SHA1"); err != nil {
 		return ""
 	}
-	return dp.Fields["SHA1"]
+	return dp.Get("SHA1")
 }
 
 // SHA256sum returns the SHA256 hash of the package content
@@ -102,17 +125,17 @@ func (dp *DebPackage) SHA256sum() string {
 		return ""
 	}
 
-	return dp.Fields["SHA256"]
+	return dp.Get("SHA256")
 }
 
 // CalcSize calculates the size of the package file
 func (dp *DebPackage) CalcSize() {
 	if dp.HasFilename() {
-		fileInfo, err := os.Stat(dp.Fields["Filename"])
+		fileInfo, err := os.Stat(dp.Get("Filename"))
 		if err != nil {
-			dp.Fields["Size"] = "0"
+			dp.Set("Size", "0")
 			return
 		}
-		dp.Fields["Size"] = strconv.FormatInt(fileInfo.Size(), 10)
+		dp.Set("Size", strconv.FormatInt(fileInfo.Size(), 10))
 	}
 }
@@ -35,27 +35,24 @@ func TestCalculateHashes(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(filepath.Base(test.debFile), func(t *testing.T) {
-			pkg := &DebPackage{
-				Fields: map[string]string{
-					"Filename": test.debFile,
-				},
-			}
+			pkg := &DebPackage{}
+			pkg.Set("Filename", test.debFile)
 
 			err := pkg.CalculateAllHashes()
 			if err != nil {
 				t.Fatalf("Failed to calculate hashes for %s: %v", test.debFile, err)
 			}
 
-			if pkg.Fields["MD5"] != test.expectedMD5 {
-				t.Errorf("Expected MD5 hash %s, got %s", test.expectedMD5, pkg.Fields["MD5"])
+			if pkg.Get("MD5") != test.expectedMD5 {
+				t.Errorf("Expected MD5 hash %s, got %s", test.expectedMD5, pkg.Get("MD5"))
 			}
 
-			if pkg.Fields["SHA1"] != test.expectedSHA1 {
-				t.Errorf("Expected SHA1 hash %s, got %s", test.expectedSHA1, pkg.Fields["SHA1"])
+			if pkg.Get("SHA1") != test.expectedSHA1 {
+				t.Errorf("Expected SHA1 hash %s, got %s", test.expectedSHA1, pkg.Get("SHA1"))
 			}
 
-			if pkg.Fields["SHA256"] != test.expectedSHA256 {
-				t.Errorf("Expected SHA256 hash %s, got %s", test.expectedSHA256, pkg.Fields["SHA256"])
+			if pkg.Get("SHA256") != test.expectedSHA256 {
+				t.Errorf("Expected SHA256 hash %s, got %s", test.expectedSHA256, pkg.Get("SHA256"))
 			}
 		})
 	}
@@ -83,11 +80,8 @@ func TestMD5sum(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(filepath.Base(test.debFile), func(t *testing.T) {
-			pkg := &DebPackage{
-				Fields: map[string]string{
-					"Filename": test.debFile,
-				},
-			}
+			pkg := &DebPackage{}
+			pkg.Set("Filename", test.debFile)
 
 			md5sum := pkg.MD5sum()
 			if md5sum != test.expectedMD5 {
@@ -119,11 +113,8 @@ func TestSHA1sum(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(filepath.Base(test.debFile), func(t *testing.T) {
-			pkg := &DebPackage{
-				Fields: map[string]string{
-					"Filename": test.debFile,
-				},
-			}
+			pkg := &DebPackage{}
+			pkg.Set("Filename", test.debFile)
 
 			sha1sum := pkg.SHA1sum()
 			if sha1sum != test.expectedSHA1 {
@@ -155,11 +146,8 @@ func TestSHA256sum(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(filepath.Base(test.debFile), func(t *testing.T) {
-			pkg := &DebPackage{
-				Fields: map[string]string{
-					"Filename": test.debFile,
-				},
-			}
+			pkg := &DebPackage{}
+			pkg.Set("Filename", test.debFile)
 
 			sha256sum := pkg.SHA256sum()
 			if sha256sum != test.expectedSHA256 {
@@ -182,27 +170,24 @@ func BenchmarkCalcHashesIndividually(b *testing.B) {
 		expectedSHA256: "c00fbabe4192ff18b5c80eff33488cc6015b3600b9fd4fb270a66b05164fa815",
 	}
 
-	pkg := &DebPackage{
-		Fields: map[string]string{
-			"Filename": test.debFile,
-		},
-	}
+	pkg := &DebPackage{}
+	pkg.Set("Filename", test.debFile)
 
 	for i := 0; i < b.N; i++ {
 		pkg.MD5sum()
 		pkg.SHA1sum()
 		pkg.SHA256sum()
 
-		if pkg.Fields["MD5"] != test.expectedMD5 {
-			b.Errorf("Expected MD5 hash %s, got %s", test.expectedMD5, pkg.Fields["MD5"])
+		if pkg.Get("MD5") != test.expectedMD5 {
+			b.Errorf("Expected MD5 hash %s, got %s", test.expectedMD5, pkg.Get("MD5"))
 		}
 
-		if pkg.Fields["SHA1"] != test.expectedSHA1 {
-			b.Errorf("Expected SHA1 hash %s, got %s", test.expectedSHA1, pkg.Fields["SHA1"])
+		if pkg.Get("SHA1") != test.expectedSHA1 {
+			b.Errorf("Expected SHA1 hash %s, got %s", test.expectedSHA1, pkg.Get("SHA1"))
 		}
 
-		if pkg.Fields["SHA256"] != test.expectedSHA256 {
-			b.Errorf("Expected SHA256 hash %s, got %s", test.expectedSHA256, pkg.Fields["SHA256"])
+		if pkg.Get("SHA256") != test.expectedSHA256 {
+			b.Errorf("Expected SHA256 hash %s, got %s", test.expectedSHA256, pkg.Get("SHA256"))
 		}
 	}
 }
@@ -220,24 +205,21 @@ func BenchmarkCalcAllHashes(b *testing.B) {
 		expectedSHA256: "c00fbabe4192ff18b5c80eff33488cc6015b3600b9fd4fb270a66b05164fa815",
 	}
 
-	pkg := &DebPackage{
-		Fields: map[string]string{
-			"Filename": test.debFile,
-		},
-	}
+	pkg := &DebPackage{}
+	pkg.Set("Filename", test.debFile)
 
 	for i := 0; i < b.N; i++ {
 		pkg.CalculateAllHashes()
-		if pkg.Fields["MD5"] != test.expectedMD5 {
-			b.Errorf("Expected MD5 hash %s, got %s", test.expectedMD5, pkg.Fields["MD5"])
+		if pkg.Get("MD5") != test.expectedMD5 {
+			b.Errorf("Expected MD5 hash %s, got %s", test.expectedMD5, pkg.Get("MD5"))
 		}
 
-		if pkg.Fields["SHA1"] != test.expectedSHA1 {
-			b.Errorf("Expected SHA1 hash %s, got %s", test.expectedSHA1, pkg.Fields["SHA1"])
+		if pkg.Get("SHA1") != test.expectedSHA1 {
+			b.Errorf("Expected SHA1 hash %s, got %s", test.expectedSHA1, pkg.Get("SHA1"))
 		}
 
-		if pkg.Fields["SHA256"] != test.expectedSHA256 {
-			b.Errorf("Expected SHA256 hash %s, got %s", test.expectedSHA256, pkg.Fields["SHA256"])
+		if pkg.Get("SHA256") != test.expectedSHA256 {
+			b.Errorf("Expected SHA256 hash %s, got %s", test.expectedSHA256, pkg.Get("SHA256"))
 		}
 	}
 }
@@ -0,0 +1,159 @@
+package dpkg
+
+import (
+	"iter"
+	"strings"
+)
+
+// Field represents a single field of a deb822 paragraph
+type Field struct {
+	Name  string
+	Value string
+	raw   string // Original text of the field, used to re-emit it unchanged
+}
+
+// Paragraph represents a deb822 paragraph (a control file, or a stanza of a status or Packages file)
+// preserving the original order and spelling of its fields; field names are case-insensitive
+// https://manpages.debian.org/bookworm/dpkg-dev/deb822.5.en.html
+type Paragraph struct {
+	fields []Field
+}
+
+// index returns the position of the field with the given name, or -1 if it does not exist
+func (p *Paragraph) index(name string) int {
+	for i := range p.fields {
+		if strings.EqualFold(p.fields[i].Name, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Get returns the value of the field, or "" if it does not exist
+func (p *Paragraph) Get(name string) string {
+	value, _ := p.Lookup(name)
+	return value
+}
+
+// Lookup returns the value of the field and whether it exists
+func (p *Paragraph) Lookup(name string) (string, bool) {
+	if i := p.index(name); i >= 0 {
+		return p.fields[i].Value, true
+	}
+	return "", false
+}
+
+// Has checks if the field exists
+func (p *Paragraph) Has(name string) bool {
+	return p.index(name) >= 0
+}
+
+// Set sets the value of the field, keeping its position and spelling if it already exists
+// or appending it at the end otherwise
+func (p *Paragraph) Set(name, value string) {
+	if i := p.index(name); i >= 0 {
+		p.fields[i].Value = value
+		p.fields[i].raw = ""
+		return
+	}
+	p.fields = append(p.fields, Field{Name: name, Value: value})
+}
+
+// Delete removes the field, if it exists
+func (p *Paragraph) Delete(name string) {
+	if i := p.index(name); i >= 0 {
+		p.fields = append(p.fields[:i:i], p.fields[i+1:]...)
+	}
+}
+
+// Len returns the number of fields
+func (p *Paragraph) Len() int {
+	return len(p.fields)
+}
+
+// Names returns the names of the fields in their original order and spelling
+func (p *Paragraph) Names() []string {
+	names := make([]string, len(p.fields))
+	for i, f := range p.fields {
+		names[i] = f.Name
+	}
+	return names
+}
+
+// All returns an iterator over the name and value of the fields in their original order
+func (p *Paragraph) All() iter.Seq2[string, string] {
+	return func(yield func(string, string) bool) {
+		for _, f := range p.fields {
+			if !yield(f.Name, f.Value) {
+				return
+			}
+		}
+	}
+}
+
+// String returns the paragraph in deb822 format; fields that were parsed and not modified
+// are emitted exactly as they were read
+func (p *Paragraph) String() string {
+	var sb strings.Builder
+	for _, f := range p.fields {
+		sb.WriteString(f.String())
+	}
+	return sb.String()
+}
+
+// String returns the field in deb822 format, including the trailing newline
+func (f Field) String() string {
+	if f.raw != "" {
+		return f.raw
+	}
+
+	lines := strings.Split(strings.TrimRight(f.Value, "\n"), "\n")
+
+	var sb strings.Builder
+	sb.WriteString(f.Name)
+	sb.WriteByte(':')
+	if lines[0] != "" {
+		sb.WriteByte(' ')
+		sb.WriteString(lines[0])
+	}
+	sb.WriteByte('\n')
+
+	// Continuation lines must start with a space, and empty lines must be escaped as " ."
+	for _, line := range lines[1:] {
+		switch {
+		case strings.TrimSpace(line) == "":
+			sb.WriteString(" .")
+		case line[0] != ' ' && line[0] != '\t':
+			sb.WriteByte(' ')
+			sb.WriteString(line)
+		default:
+			sb.WriteString(line)
+		}
+		sb.WriteByte('\n')
+	}
+
+	return sb.String()
+}
+
+// isValidFieldName checks if the name can be used as a deb822 field name, that is
+// printable US-ASCII characters other than colon, not starting with "#" or "-"
+func isValidFieldName(name string) bool {
+	if name == "" || name[0] == '#' || name[0] == '-' {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		if name[i] <= ' ' || name[i] > '~' || name[i] == ':' {
+			return false
+		}
+	}
+	return true
+}
+
+// add appends a parsed field, replacing the previous one if it is repeated
+func (p *Paragraph) add(name, value, raw string) {
+	if i := p.index(name); i >= 0 {
+		p.fields[i] = Field{Name: name, Value: value, raw: raw}
+		return
+	}
+	p.fields = append(p.fields, Field{Name: name, Value: value, raw: raw})
+}
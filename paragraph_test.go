@@ -0,0 +1,105 @@
+package dpkg
+
+import (
+	"bytes"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// TestParagraphRoundTrip tests that parsed paragraphs are re-emitted byte-identical
+func TestParagraphRoundTrip(t *testing.T) {
+	tests := []string{
+		"testdata/control/control-1",
+		"testdata/control/control-2",
+	}
+
+	for _, filePath := range tests {
+		t.Run(filePath, func(t *testing.T) {
+			content, err := os.ReadFile(filePath)
+			if err != nil {
+				t.Fatalf("Failed to read file %s: %v", filePath, err)
+			}
+
+			pkg, err := parseControlFile(bytes.NewReader(content))
+			if err != nil {
+				t.Fatalf("Failed to parse control file from %s: %v", filePath, err)
+			}
+
+			if got := pkg.String(); got != string(content) {
+				t.Errorf("Paragraph from %s is not byte-identical:\n%s\nwant:\n%s", filePath, got, content)
+			}
+		})
+	}
+}
+
+// TestParagraphPreservesSpelling tests that irregular spacing and spelling survive a round trip
+func TestParagraphPreservesSpelling(t *testing.T) {
+	content := "package:vim\nVERSION:   1.0  \nDescription:\n foo\n .\n bar\n"
+
+	pkg, err := parseControlFile(strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("Failed to parse control file: %v", err)
+	}
+
+	if got := pkg.String(); got != content {
+		t.Errorf("String() = %q; want %q", got, content)
+	}
+	if got := pkg.Get("Version"); got != "1.0" {
+		t.Errorf("Get(Version) = %q; want %q", got, "1.0")
+	}
+	if got := pkg.Get("description"); got != "\n foo\n .\n bar" {
+		t.Errorf("Get(description) = %q; want %q", got, "\n foo\n .\n bar")
+	}
+}
+
+// TestParagraphCaseInsensitive tests the Get, Set and Delete methods of Paragraph
+func TestParagraphCaseInsensitive(t *testing.T) {
+	var p Paragraph
+	p.Set("Package", "vim")
+	p.Set("Version", "1.0")
+	p.Set("Architecture", "amd64")
+
+	if got := p.Get("PACKAGE"); got != "vim" {
+		t.Errorf("Get(PACKAGE) = %q; want %q", got, "vim")
+	}
+
+	// Setting an existing field keeps its position and spelling
+	p.Set("version", "2.0")
+	if got := p.Names(); !slices.Equal(got, []string{"Package", "Version", "Architecture"}) {
+		t.Errorf("Names() = %v; want [Package Version Architecture]", got)
+	}
+	if got := p.Get("Version"); got != "2.0" {
+		t.Errorf("Get(Version) = %q; want %q", got, "2.0")
+	}
+
+	p.Delete("VERSION")
+	if p.Has("Version") {
+		t.Errorf("Expected Version to be deleted")
+	}
+	if _, ok := p.Lookup("version"); ok {
+		t.Errorf("Expected Lookup(version) to report a missing field")
+	}
+
+	want := "Package: vim\nArchitecture: amd64\n"
+	if got := p.String(); got != want {
+		t.Errorf("String() = %q; want %q", got, want)
+	}
+}
+
+// TestParagraphSetRewritesField tests that modified fields are re-emitted in canonical form
+func TestParagraphSetRewritesField(t *testing.T) {
+	content := "Package:vim\nStatus: install ok installed\nVersion:1.0\n"
+
+	pkg, err := parseControlFile(strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("Failed to parse control file: %v", err)
+	}
+
+	pkg.Set("status", "deinstall ok config-files")
+	want := "Package:vim\nStatus: deinstall ok config-files\nVersion:1.0\n"
+	if got := pkg.String(); got != want {
+		t.Errorf("String() = %q; want %q", got, want)
+	}
+}
@@ -2,41 +2,173 @@ package dpkg
 
 import (
 	"bufio"
-	"bytes"
 	"fmt"
 	"io"
+	"iter"
 	"os"
 	"strings"
+	"unicode/utf8"
 )
 
+// ParseError represents a syntax error found while parsing deb822 data in strict mode
+type ParseError struct {
+	File   string
+	Line   int
+	Column int
+	Reason string
+}
+
+// Error returns the error message, prefixed with the position of the error
+func (e *ParseError) Error() string {
+	file := e.File
+	if file == "" {
+		file = "<input>"
+	}
+	return fmt.Sprintf("go-apt/dpkg: %s:%d:%d: %s", file, e.Line, e.Column, e.Reason)
+}
+
+// ParagraphReader reads deb822 paragraphs, such as the stanzas of a status or Packages file,
+// one at a time from an underlying reader
+type ParagraphReader struct {
+	// Strict enables the reporting of syntax errors as *ParseError instead of
+	// accepting them the way messy real-world status files require
+	Strict bool
+	// File is the name reported in the errors
+	File string
+
+	r    *bufio.Reader
+	line int
+}
+
+// NewParagraphReader creates a new ParagraphReader reading from r
+func NewParagraphReader(r io.Reader) *ParagraphReader {
+	return &ParagraphReader{
+		r: bufio.NewReader(r),
+	}
+}
+
+// Next reads the next paragraph, returning io.EOF when there are no more paragraphs
+func (pr *ParagraphReader) Next() (*Paragraph, error) {
+	var buffer strings.Builder
+	first := 0
+
+	for {
+		line, err := pr.r.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, fmt.Errorf("go-apt/dpkg: failed to read paragraph: %w", err)
+		}
+		if line != "" {
+			pr.line++
+		}
+
+		// A blank line ends the paragraph, while leading blank lines are skipped
+		if strings.TrimRight(line, "\r\n") == "" {
+			if buffer.Len() > 0 {
+				return pr.parse(buffer.String(), first)
+			}
+			if err == io.EOF {
+				return nil, io.EOF
+			}
+			continue
+		}
+
+		if buffer.Len() == 0 {
+			first = pr.line
+		}
+		buffer.WriteString(line)
+
+		// Add the last paragraph if the input does not end with a blank line
+		if err == io.EOF {
+			if !strings.HasSuffix(line, "\n") {
+				buffer.WriteString("\n")
+			}
+			return pr.parse(buffer.String(), first)
+		}
+	}
+}
+
+// parse parses a paragraph starting at the given line of the input
+func (pr *ParagraphReader) parse(content string, line int) (*Paragraph, error) {
+	parser := paragraphParser{strict: pr.Strict, file: pr.File, line: line}
+	return parser.parse(content)
+}
+
+// Packages returns an iterator over the remaining paragraphs as packages
+func (pr *ParagraphReader) Packages() iter.Seq2[*DebPackage, error] {
+	return func(yield func(*DebPackage, error) bool) {
+		for {
+			p, err := pr.Next()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if !yield(&DebPackage{Paragraph: *p}, nil) {
+				return
+			}
+		}
+	}
+}
+
 // parseControlFile parses the control file content into a DebPackage struct
 func parseControlFile(reader io.Reader) (*DebPackage, error) {
-	pkg := &DebPackage{}
-	pkg.Fields = make(map[string]string)
-
 	// Read the content of the control file
 	content, err := io.ReadAll(reader)
 	if err != nil {
 		return nil, err
 	}
 
-	// Convert the content to a string and split into lines
-	lines := strings.Split(string(content), "\n")
+	p, err := parseParagraph(string(content))
+	if err != nil {
+		return nil, err
+	}
+
+	return &DebPackage{Paragraph: *p}, nil
+}
+
+// parseParagraph parses the content of a single deb822 paragraph in lenient mode
+func parseParagraph(content string) (*Paragraph, error) {
+	parser := paragraphParser{line: 1}
+	return parser.parse(content)
+}
+
+// paragraphParser parses a single deb822 paragraph, keeping track of its position in the input
+type paragraphParser struct {
+	strict bool
+	file   string
+	line   int // Line number of the first line of the content
+}
+
+// parse parses the content of the paragraph
+func (pp *paragraphParser) parse(content string) (*Paragraph, error) {
+	p := &Paragraph{}
 
-	// Iterate over the lines to fill the struct
+	// Split the content into lines
+	lines := strings.Split(content, "\n")
+
+	// Iterate over the lines to fill the paragraph
 	for i := 0; i < len(lines); i++ {
 		line := lines[i]
+		first := i
 
 		// Skip empty lines
 		if line == "" {
 			continue
 		}
 
+		if pp.strict {
+			if err := pp.checkLine(p, line, i); err != nil {
+				return nil, err
+			}
+		}
+
 		// Split the line into key and value
 		parts := strings.SplitN(line, ":", 2)
 		if len(parts) < 2 {
 			// If there's no ":", treat the entire line as a key with an empty value
-			pkg.Fields[strings.TrimSpace(parts[0])] = ""
+			p.add(strings.TrimSpace(parts[0]), "", line+"\n")
 			continue
 		}
 
@@ -46,75 +178,109 @@ func parseControlFile(reader io.Reader) (*DebPackage, error) {
 		// Check if the next lines are continuations (start with space or tab)
 		for i+1 < len(lines) && (strings.HasPrefix(lines[i+1], " ") || strings.HasPrefix(lines[i+1], "\t")) {
 			i++
+			if pp.strict && !utf8.ValidString(lines[i]) {
+				return nil, pp.errorf(i, invalidUTF8Column(lines[i]), "invalid UTF-8 data")
+			}
 			value += "\n" + lines[i]
 		}
 
-		// Store the key-value pair in the map
-		pkg.Fields[key] = value
+		// Store the key-value pair along with its original text
+		p.add(key, value, strings.Join(lines[first:i+1], "\n")+"\n")
 	}
 
-	return pkg, nil
+	return p, nil
 }
 
-// readPackageBlocks reads the statusFile and returns a slice of []byte,
-// where each []byte contains the content of a package block.
-func readPackageBlocks(statusFile string) ([][]byte, error) {
-	file, err := os.Open(statusFile)
-	if err != nil {
-		return nil, ErrNoDpkgStatusFile
+// checkLine checks the line starting a field against the deb822 syntax
+// https://manpages.debian.org/bookworm/dpkg-dev/deb822.5.en.html#SYNTAX
+func (pp *paragraphParser) checkLine(p *Paragraph, line string, i int) error {
+	if !utf8.ValidString(line) {
+		return pp.errorf(i, invalidUTF8Column(line), "invalid UTF-8 data")
 	}
-	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
-	var blocks [][]byte
-	var buffer bytes.Buffer
+	// Continuation lines are consumed along with their field, so this one has none
+	if line[0] == ' ' || line[0] == '\t' {
+		return pp.errorf(i, 1, "continuation line before any field")
+	}
 
-	for scanner.Scan() {
-		line := scanner.Text()
+	name, _, found := strings.Cut(line, ":")
+	if !found {
+		return pp.errorf(i, len(line)+1, "missing colon after field name %q", line)
+	}
+	// Blanks are allowed between the name and the colon, as with dpkg
+	name = strings.TrimRight(name, " \t")
 
-		// Check if it's the end of the block (blank line)
-		if line != "" {
-			// Add the line to the buffer
-			buffer.WriteString(line)
-			buffer.WriteString("\n") // Keep line breaks
-		} else {
-			// Create a copy of the buffer and add it to the slice of blocks
-			block := make([]byte, buffer.Len())
-			copy(block, buffer.Bytes()) // Copy the buffer content to a new slice
-			blocks = append(blocks, block)
-			buffer.Reset() // Clear the buffer for the next block
+	if name == "" {
+		return pp.errorf(i, 1, "empty field name")
+	}
+	if name[0] == '#' || name[0] == '-' {
+		return pp.errorf(i, 1, "invalid field name %q", name)
+	}
+	for col := 0; col < len(name); col++ {
+		if name[col] <= ' ' || name[col] > '~' {
+			return pp.errorf(i, col+1, "invalid character in field name %q", name)
 		}
 	}
 
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("error reading the status file: %w", err)
+	if p.Has(name) {
+		return pp.errorf(i, 1, "duplicate field %q", name)
 	}
 
-	// Add the last block if the file does not end with a blank line
-	if buffer.Len() > 0 {
-		block := make([]byte, buffer.Len())
-		copy(block, buffer.Bytes()) // Copy the buffer content to a new slice
-		blocks = append(blocks, block)
+	return nil
+}
+
+// errorf returns a *ParseError for the i-th line of the content
+func (pp *paragraphParser) errorf(i, column int, format string, args ...any) error {
+	return &ParseError{
+		File:   pp.file,
+		Line:   pp.line + i,
+		Column: column,
+		Reason: fmt.Sprintf(format, args...),
 	}
+}
 
-	return blocks, nil
+// invalidUTF8Column returns the column of the first invalid UTF-8 byte in the line
+func invalidUTF8Column(line string) int {
+	for i, r := range line {
+		if r == utf8.RuneError {
+			if _, size := utf8.DecodeRuneInString(line[i:]); size <= 1 {
+				return i + 1
+			}
+		}
+	}
+	return 1
 }
 
-// parseStatusFile reads the statusFile file and returns a list of packages
-func parseStatusFile(statusFile string) ([]DebPackage, error) {
-	blocks, err := readPackageBlocks(statusFile)
-	if err != nil {
-		return nil, err
+// readStatusFile returns an iterator over the packages of the statusFile,
+// reading them one at a time
+func readStatusFile(statusFile string, strict bool) iter.Seq2[*DebPackage, error] {
+	return func(yield func(*DebPackage, error) bool) {
+		file, err := os.Open(statusFile)
+		if err != nil {
+			yield(nil, fmt.Errorf("%w: %w", ErrNoDpkgStatusFile, err))
+			return
+		}
+		defer file.Close()
+
+		reader := NewParagraphReader(file)
+		reader.Strict = strict
+		reader.File = statusFile
+
+		for pkg, err := range reader.Packages() {
+			if !yield(pkg, err) {
+				return
+			}
+		}
 	}
+}
 
+// parseStatusFile reads the statusFile file and returns a list of packages
+func parseStatusFile(statusFile string, strict bool) ([]DebPackage, error) {
 	var packages []DebPackage
 
-	for _, block := range blocks {
-		// Create a new io.Reader for each block
-		reader := bytes.NewReader(block)
-		pkg, err := parseControlFile(reader)
+	for pkg, err := range readStatusFile(statusFile, strict) {
 		if err != nil {
-			return nil, fmt.Errorf("error processing the block: %w", err)
+			return nil, err
 		}
 		packages = append(packages, *pkg)
 	}
@@ -1,7 +1,11 @@
 package dpkg
 
 import (
+	"errors"
+	"io"
 	"os"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -9,30 +13,26 @@ import (
 func TestParseControlFile(t *testing.T) {
 	tests := []struct {
 		filePath string
-		expected *DebPackage
+		expected map[string]string
 	}{
 		{
 			"testdata/control/control-1",
-			&DebPackage{
-				Fields: map[string]string{
-					"Package":      "vim-tiny",
-					"Version":      "1:7.0-122+1etch5",
-					"Architecture": "amd64",
-					"Maintainer":   "Debian VIM Maintainers <pkg-vim-maintainers@lists.alioth.debian.org>",
-					"Description":  "Vi IMproved - enhanced vi editor - compact version\n Vim is an almost compatible version of the UNIX editor Vi.\n .\n Many new features have been added: multi level undo, syntax\n highlighting, command line history, on-line help, filename\n completion, block operations, folding, Unicode support, etc.\n .\n This package contains a minimal version of vim compiled with no\n GUI and a small subset of features in order to keep small the\n package size. This package does not depend on the vim-runtime\n package, but installing it you will get its additional benefits\n (online documentation, plugins, ...).",
-				},
+			map[string]string{
+				"Package":      "vim-tiny",
+				"Version":      "1:7.0-122+1etch5",
+				"Architecture": "amd64",
+				"Maintainer":   "Debian VIM Maintainers <pkg-vim-maintainers@lists.alioth.debian.org>",
+				"Description":  "Vi IMproved - enhanced vi editor - compact version\n Vim is an almost compatible version of the UNIX editor Vi.\n .\n Many new features have been added: multi level undo, syntax\n highlighting, command line history, on-line help, filename\n completion, block operations, folding, Unicode support, etc.\n .\n This package contains a minimal version of vim compiled with no\n GUI and a small subset of features in order to keep small the\n package size. This package does not depend on the vim-runtime\n package, but installing it you will get its additional benefits\n (online documentation, plugins, ...).",
 			},
 		},
 		{
 			"testdata/control/control-2",
-			&DebPackage{
-				Fields: map[string]string{
-					"Package":      "vim-tiny",
-					"Version":      "2:9.1.1113-1",
-					"Architecture": "amd64",
-					"Maintainer":   "Debian Vim Maintainers <team+vim@tracker.debian.org>",
-					"Description":  "Vi IMproved - enhanced vi editor - compact version\n Vim is an almost compatible version of the UNIX editor Vi.\n .\n This package contains a minimal version of Vim compiled with no GUI and\n a small subset of features. This package's sole purpose is to provide\n the vi binary for base installations.\n .\n If a vim binary is wanted, try one of the following more featureful\n packages: vim, vim-nox, vim-motif, or vim-gtk3.",
-				},
+			map[string]string{
+				"Package":      "vim-tiny",
+				"Version":      "2:9.1.1113-1",
+				"Architecture": "amd64",
+				"Maintainer":   "Debian Vim Maintainers <team+vim@tracker.debian.org>",
+				"Description":  "Vi IMproved - enhanced vi editor - compact version\n Vim is an almost compatible version of the UNIX editor Vi.\n .\n This package contains a minimal version of Vim compiled with no GUI and\n a small subset of features. This package's sole purpose is to provide\n the vi binary for base installations.\n .\n If a vim binary is wanted, try one of the following more featureful\n packages: vim, vim-nox, vim-motif, or vim-gtk3.",
 			},
 		},
 	}
@@ -49,28 +49,87 @@ func TestParseControlFile(t *testing.T) {
 			t.Fatalf("Failed to parse control file from %s: %v", test.filePath, err)
 		}
 
-		if pkg.Fields["Package"] != test.expected.Fields["Package"] || pkg.Fields["Version"] != test.expected.Fields["Version"] || pkg.Fields["Architecture"] != test.expected.Fields["Architecture"] || pkg.Fields["Maintainer"] != test.expected.Fields["Maintainer"] || pkg.Fields["Description"] != test.expected.Fields["Description"] {
+		if pkg.Get("Package") != test.expected["Package"] || pkg.Get("Version") != test.expected["Version"] || pkg.Get("Architecture") != test.expected["Architecture"] || pkg.Get("Maintainer") != test.expected["Maintainer"] || pkg.Get("Description") != test.expected["Description"] {
 			t.Errorf("Parsed package metadata from %s does not match expected values", test.filePath)
 		}
 	}
 }
 
-// TestReadPackageBlocks tests the readPackageBlocks function
-func TestReadPackageBlocks(t *testing.T) {
+// TestParagraphReader tests the ParagraphReader type
+func TestParagraphReader(t *testing.T) {
 	statusFile := "testdata/status"
-	blocks, err := readPackageBlocks(statusFile)
+	file, err := os.Open(statusFile)
 	if err != nil {
-		t.Fatalf("Failed to read package blocks from %s: %v", statusFile, err)
+		t.Fatalf("Failed to open file %s: %v", statusFile, err)
 	}
+	defer file.Close()
 
-	if len(blocks) == 0 {
-		t.Errorf("Expected non-zero number of blocks, got %d", len(blocks))
+	var names []string
+	reader := NewParagraphReader(file)
+	for {
+		p, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Failed to read paragraph from %s: %v", statusFile, err)
+		}
+		if p.Len() == 0 {
+			t.Errorf("Paragraph %d is empty", len(names))
+		}
+		names = append(names, p.Get("Package"))
+	}
+
+	want := []string{"adduser", "apparmor", "apt", "apt-listchanges", "apt-mirror"}
+	if !slices.Equal(names, want) {
+		t.Errorf("Read packages %v; want %v", names, want)
 	}
+}
+
+// TestParagraphReaderBlankLines tests that extra blank lines and long lines are handled
+func TestParagraphReaderBlankLines(t *testing.T) {
+	long := strings.Repeat("x", 128*1024)
+	content := "\n\nPackage: a\nDescription: " + long + "\n\n\n\nPackage: b\n\n"
 
-	// Additional checks to ensure blocks are not empty
-	for i, block := range blocks {
-		if len(block) == 0 {
-			t.Errorf("Block %d is empty", i)
+	var names []string
+	for pkg, err := range NewParagraphReader(strings.NewReader(content)).Packages() {
+		if err != nil {
+			t.Fatalf("Failed to read paragraph: %v", err)
+		}
+		names = append(names, pkg.Get("Package"))
+		if pkg.Get("Package") == "a" && pkg.Get("Description") != long {
+			t.Errorf("Long Description was not read entirely")
+		}
+	}
+
+	if !slices.Equal(names, []string{"a", "b"}) {
+		t.Errorf("Read packages %v; want [a b]", names)
+	}
+}
+
+// TestDpkgPackages tests the Packages method of Dpkg
+func TestDpkgPackages(t *testing.T) {
+	d := &Dpkg{StatusFileLocation: "testdata/status"}
+
+	var names []string
+	for pkg, err := range d.Packages() {
+		if err != nil {
+			t.Fatalf("Failed to read packages: %v", err)
+		}
+		names = append(names, pkg.Get("Package"))
+		if len(names) == 2 {
+			break
+		}
+	}
+
+	if !slices.Equal(names, []string{"adduser", "apparmor"}) {
+		t.Errorf("Read packages %v; want [adduser apparmor]", names)
+	}
+
+	d = &Dpkg{StatusFileLocation: "testdata/missing"}
+	for _, err := range d.Packages() {
+		if !errors.Is(err, ErrNoDpkgStatusFile) || !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("Expected ErrNoDpkgStatusFile wrapping os.ErrNotExist, got %v", err)
 		}
 	}
 }
@@ -78,15 +137,15 @@ func TestReadPackageBlocks(t *testing.T) {
 // TestParseStatusFile tests the parseStatusFile function
 func TestParseStatusFile(t *testing.T) {
 	statusFile := "testdata/status"
-	expectedPackages := []DebPackage{
-		{Fields: map[string]string{"Package": "adduser", "Version": "3.134"}},
-		{Fields: map[string]string{"Package": "apparmor", "Version": "3.0.8-3"}},
-		{Fields: map[string]string{"Package": "apt", "Version": "2.6.1"}},
-		{Fields: map[string]string{"Package": "apt-listchanges", "Version": "3.24"}},
-		{Fields: map[string]string{"Package": "apt-mirror", "Version": "0.5.4-2"}},
+	expectedPackages := []map[string]string{
+		{"Package": "adduser", "Version": "3.134"},
+		{"Package": "apparmor", "Version": "3.0.8-3"},
+		{"Package": "apt", "Version": "2.6.1"},
+		{"Package": "apt-listchanges", "Version": "3.24"},
+		{"Package": "apt-mirror", "Version": "0.5.4-2"},
 	}
 
-	packages, err := parseStatusFile(statusFile)
+	packages, err := parseStatusFile(statusFile, false)
 	if err != nil {
 		t.Fatalf("Failed to parse status file from %s: %v", statusFile, err)
 	}
@@ -97,8 +156,88 @@ func TestParseStatusFile(t *testing.T) {
 
 	for i, pkg := range packages {
 		expected := expectedPackages[i]
-		if pkg.Fields["Package"] != expected.Fields["Package"] || pkg.Fields["Version"] != expected.Fields["Version"] {
+		if pkg.Get("Package") != expected["Package"] || pkg.Get("Version") != expected["Version"] {
 			t.Errorf("Package %d does not match expected values. Got %+v, expected %+v", i, pkg, expected)
 		}
 	}
 }
+
+// TestParagraphReaderStrict tests the errors reported by the strict mode of ParagraphReader
+func TestParagraphReaderStrict(t *testing.T) {
+	tests := []struct {
+		name       string
+		content    string
+		wantLine   int
+		wantColumn int
+		wantReason string
+	}{
+		{"missing colon", "Package: a\n\nPackage: b\nVersion\n", 4, 8, "missing colon"},
+		{"duplicate field", "Package: a\nVersion: 1\npackage: b\n", 3, 1, "duplicate field"},
+		{"duplicate field with blanks", "Package : a\nPackage: b\n", 2, 1, "duplicate field"},
+		{"continuation first", "\n\n continued\nPackage: a\n", 3, 1, "continuation line"},
+		{"comment", "Package: a\n#Version: 1\n", 2, 1, "invalid field name"},
+		{"hyphen", "-Package: a\n", 1, 1, "invalid field name"},
+		{"empty name", "Package: a\n: value\n", 2, 1, "empty field name"},
+		{"space in name", "Package: a\nInstalled Size: 1\n", 2, 10, "invalid character"},
+		{"invalid utf-8 value", "Package: a\nMaintainer: J\xe9r\xf4me\n", 2, 14, "invalid UTF-8"},
+		{"invalid utf-8 continuation", "Package: a\nDescription: foo\n bar \xff\n", 3, 6, "invalid UTF-8"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The lenient mode accepts the input
+			for _, err := range NewParagraphReader(strings.NewReader(tt.content)).Packages() {
+				if err != nil {
+					t.Fatalf("Lenient mode failed to read paragraph: %v", err)
+				}
+			}
+
+			reader := NewParagraphReader(strings.NewReader(tt.content))
+			reader.Strict = true
+			reader.File = "status"
+
+			var err error
+			for _, err = range reader.Packages() {
+				if err != nil {
+					break
+				}
+			}
+
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("Expected *ParseError, got %v", err)
+			}
+			if parseErr.File != "status" || parseErr.Line != tt.wantLine || parseErr.Column != tt.wantColumn {
+				t.Errorf("Error at %s:%d:%d; want status:%d:%d", parseErr.File, parseErr.Line, parseErr.Column, tt.wantLine, tt.wantColumn)
+			}
+			if !strings.Contains(parseErr.Reason, tt.wantReason) {
+				t.Errorf("Error reason %q; want it to contain %q", parseErr.Reason, tt.wantReason)
+			}
+		})
+	}
+}
+
+// TestParagraphReaderStrictValid tests that the strict mode accepts valid status files
+func TestParagraphReaderStrictValid(t *testing.T) {
+	d := &Dpkg{StatusFileLocation: "testdata/status", Strict: true}
+
+	packages, err := d.List()
+	if err != nil {
+		t.Fatalf("Strict mode failed to read %s: %v", d.StatusFileLocation, err)
+	}
+	if len(packages) != 5 {
+		t.Errorf("Expected 5 packages, got %d", len(packages))
+	}
+
+	// Blanks between the field name and the colon are accepted
+	reader := NewParagraphReader(strings.NewReader("Package : foo\nVersion\t: 1.0\n"))
+	reader.Strict = true
+	for pkg, err := range reader.Packages() {
+		if err != nil {
+			t.Fatalf("Strict mode failed to read blanks before the colon: %v", err)
+		}
+		if pkg.Get("Package") != "foo" || pkg.Get("Version") != "1.0" {
+			t.Errorf("Package = %q, Version = %q; want foo, 1.0", pkg.Get("Package"), pkg.Get("Version"))
+		}
+	}
+}
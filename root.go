@@ -0,0 +1,118 @@
+package dpkg
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// root returns the root directory of the installation
+func (d *Dpkg) root() string {
+	if d.Root == "" {
+		return "/"
+	}
+	return d.Root
+}
+
+// adminPath returns the location of a file of the dpkg database, given by the elements of
+// its path in the admin directory. The admin directory is AdminDir when it is set, or the
+// directory of StatusFileLocation when it is changed, which are locations of the host;
+// otherwise it is /var/lib/dpkg below Root, and the symlinks of the whole path are
+// resolved relative to Root, see rootPath.
+func (d *Dpkg) adminPath(elem ...string) string {
+	switch {
+	case d.AdminDir != "":
+		return filepath.Join(append([]string{d.AdminDir}, elem...)...)
+	case d.StatusFileLocation != "" && d.StatusFileLocation != DPKG_DATABASE:
+		return filepath.Join(append([]string{filepath.Dir(d.StatusFileLocation)}, elem...)...)
+	}
+	return d.rootPath(path.Join(append([]string{DPKG_ADMINDIR}, elem...)...))
+}
+
+// statusFile returns the location of the status file, StatusFileLocation when it is
+// changed, and the status file of the admin directory otherwise
+func (d *Dpkg) statusFile() string {
+	if d.StatusFileLocation != "" && d.StatusFileLocation != DPKG_DATABASE {
+		return d.StatusFileLocation
+	}
+	return d.adminPath("status")
+}
+
+// rootPath returns the location of an absolute path of the installation, see resolveInRoot.
+// When the path cannot be resolved, it is joined to the root directory so that the error is
+// reported when the file is opened.
+func (d *Dpkg) rootPath(name string) string {
+	resolved, err := resolveInRoot(d.root(), name)
+	if err != nil {
+		return filepath.Join(d.root(), filepath.FromSlash(name))
+	}
+	return resolved
+}
+
+// resolveInRoot returns the location of name in the host file system, following the
+// symlinks of its components as if root was the root directory: absolute targets are
+// relative to root and ".." never goes above it. Components which do not exist are kept
+// as they are.
+func resolveInRoot(root, name string) (string, error) {
+	if root == "/" {
+		return path.Clean("/" + filepath.ToSlash(name)), nil
+	}
+
+	var resolved string // Resolved path relative to root
+	pending := strings.Split(filepath.ToSlash(name), "/")
+	missing := false
+	links := 0
+
+	for len(pending) > 0 {
+		component := pending[0]
+		pending = pending[1:]
+
+		switch component {
+		case "", ".":
+			continue
+		case "..":
+			if resolved = path.Dir(resolved); resolved == "." {
+				resolved = ""
+			}
+			continue
+		}
+
+		next := path.Join(resolved, component)
+		if missing {
+			resolved = next
+			continue
+		}
+
+		location := filepath.Join(root, filepath.FromSlash(next))
+		info, err := os.Lstat(location)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			missing = true
+			resolved = next
+			continue
+		case err != nil:
+			return "", err
+		case info.Mode()&fs.ModeSymlink == 0:
+			resolved = next
+			continue
+		}
+
+		if links++; links > maxSymlinks {
+			return "", fmt.Errorf("go-apt/dpkg: too many levels of symbolic links in %s", name)
+		}
+		target, err := os.Readlink(location)
+		if err != nil {
+			return "", err
+		}
+		if strings.HasPrefix(target, "/") {
+			resolved = ""
+		}
+		pending = append(strings.Split(target, "/"), pending...)
+	}
+
+	return filepath.Join(root, filepath.FromSlash(resolved)), nil
+}
@@ -0,0 +1,190 @@
+package dpkg
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestResolveInRoot tests the resolution of symlinks relative to a root directory
+func TestResolveInRoot(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "a"), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "a", "b"), nil, 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	links := map[string]string{
+		"absolute": "/a",
+		"relative": "a",
+		"up":       "../../../a",
+		"file":     "/a/b",
+		"loop":     "loop",
+	}
+	for name, target := range links {
+		if err := os.Symlink(target, filepath.Join(root, name)); err != nil {
+			t.Fatalf("Failed to create symlink: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"/a/b", "a/b"},
+		{"/absolute/b", "a/b"},
+		{"relative/b", "a/b"},
+		{"/up/b", "a/b"},
+		{"/file", "a/b"},
+		{"/../../a/./b", "a/b"},
+		{"/missing/../a/b", "a/b"},
+		{"/missing/c/../../../etc", "etc"},
+		{"/", ""},
+	}
+
+	for _, test := range tests {
+		got, err := resolveInRoot(root, test.name)
+		if err != nil {
+			t.Errorf("resolveInRoot(%s) failed: %v", test.name, err)
+			continue
+		}
+		if want := filepath.Join(root, test.want); got != want {
+			t.Errorf("resolveInRoot(%s) = %s; want %s", test.name, got, want)
+		}
+	}
+
+	if _, err := resolveInRoot(root, "/loop/b"); err == nil {
+		t.Errorf("Expected an error for a symlink loop")
+	}
+	if got, err := resolveInRoot("/", "/usr/../etc/"); err != nil || got != "/etc" {
+		t.Errorf("resolveInRoot(/, /usr/../etc/) = %s, %v; want /etc", got, err)
+	}
+}
+
+// TestDpkgRoot tests that the dpkg database and the installed files are read below Root,
+// resolving absolute symlinks relative to it
+func TestDpkgRoot(t *testing.T) {
+	root := newTestRoot(t)
+	// Move the database and a directory behind absolute symlinks, which only resolve in root
+	moves := [][2]string{{"var/lib/dpkg", "srv/dpkg"}, {"usr/share/doc/vim-tiny", "usr/share/doc/vim-common"}}
+	for _, move := range moves {
+		from, to := filepath.Join(root, move[0]), filepath.Join(root, move[1])
+		if err := os.MkdirAll(filepath.Dir(to), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.Rename(from, to); err != nil {
+			t.Fatalf("Failed to move %s: %v", move[0], err)
+		}
+		if err := os.Symlink("/"+move[1], from); err != nil {
+			t.Fatalf("Failed to create symlink: %v", err)
+		}
+	}
+
+	d := NewDpkg()
+	d.Root = root
+	packages, err := d.List()
+	if err != nil || len(packages) != 5 {
+		t.Fatalf("List() = %d packages, %v; want 5", len(packages), err)
+	}
+	if files, err := d.Files("nano"); err != nil || len(files) != 3 {
+		t.Errorf("Files(nano) = %v, %v", files, err)
+	}
+	report, err := d.Verify("vim-tiny")
+	if err != nil || !report.OK() {
+		t.Errorf("Verify(vim-tiny) = %+v, %v", report, err)
+	}
+
+	// The admin directory is a location of the host
+	d = &Dpkg{Root: filepath.Join(root, "missing"), AdminDir: filepath.Join(root, "srv/dpkg")}
+	if packages, err := d.List(); err != nil || len(packages) != 5 {
+		t.Errorf("List() with AdminDir = %d packages, %v; want 5", len(packages), err)
+	}
+	if report, err := d.Verify("adduser"); err != nil || report.OK() {
+		t.Errorf("Verify(adduser) with a missing root = %+v, %v; want missing files", report, err)
+	}
+}
+
+// TestDpkgRootAdminFiles tests that the files of the dpkg database are read below Root
+// when they are absolute symlinks
+func TestDpkgRootAdminFiles(t *testing.T) {
+	plain, linked := newTestRoot(t), newTestRoot(t)
+	names := []string{
+		"status", "status-old", "available", "updates/0001", "diversions", "arch",
+		"alternatives/editor", "info/nano.list", "info/vim-tiny.list", "info/vim-tiny.md5sums",
+	}
+	for _, root := range []string{plain, linked} {
+		update := "Package: curl\nStatus: hold ok installed\nArchitecture: amd64\nVersion: 8.12.1-3\n"
+		if err := os.MkdirAll(filepath.Join(root, "var/lib/dpkg/updates"), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(root, "var/lib/dpkg/updates/0001"), []byte(update), 0644); err != nil {
+			t.Fatalf("Failed to write update: %v", err)
+		}
+	}
+
+	// Every file of the database is moved out of it, behind an absolute symlink
+	for _, name := range names {
+		from, to := filepath.Join(linked, "var/lib/dpkg", name), filepath.Join(linked, "db", name)
+		if err := os.MkdirAll(filepath.Dir(to), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.Rename(from, to); err != nil {
+			t.Fatalf("Failed to move %s: %v", name, err)
+		}
+		if err := os.Symlink("/db/"+name, from); err != nil {
+			t.Fatalf("Failed to create symlink: %v", err)
+		}
+	}
+
+	read := func(root string) []any {
+		d := &Dpkg{Root: root}
+		status, err := d.List()
+		if err != nil || len(status) == 0 {
+			t.Fatalf("List() = %d packages, %v", len(status), err)
+		}
+		var results []any
+		for _, db := range []Database{DatabaseStatusOld, DatabaseAvailable} {
+			other := *d
+			other.Database = db
+			packages, err := other.List()
+			results = append(results, len(packages), err)
+		}
+		files, err := d.Files("nano")
+		report, verifyErr := d.Verify("vim-tiny")
+		diversions, diversionsErr := d.Diversions()
+		archs, archsErr := d.Architectures()
+		alternative, alternativeErr := d.Alternative("editor")
+		return append(results, status[len(status)-1].String(), files, err, report.OK(), len(report.Files), verifyErr,
+			diversions, diversionsErr, archs, archsErr, alternative, alternativeErr)
+	}
+	if got, want := read(linked), read(plain); !reflect.DeepEqual(got, want) {
+		t.Errorf("Database read through symlinks =\n%v\nwant\n%v", got, want)
+	}
+}
+
+// TestDpkgAdminDir tests the locations of the dpkg database
+func TestDpkgAdminDir(t *testing.T) {
+	tests := []struct {
+		d          Dpkg
+		statusFile string
+		infoDir    string
+	}{
+		{*NewDpkg(), "/var/lib/dpkg/status", "/var/lib/dpkg/info"},
+		{Dpkg{}, "/var/lib/dpkg/status", "/var/lib/dpkg/info"},
+		{Dpkg{StatusFileLocation: "/tmp/db/status"}, "/tmp/db/status", "/tmp/db/info"},
+		{Dpkg{StatusFileLocation: DPKG_DATABASE, Root: "/nonexistent"}, "/nonexistent/var/lib/dpkg/status", "/nonexistent/var/lib/dpkg/info"},
+		{Dpkg{Root: "/nonexistent", AdminDir: "/tmp/db"}, "/tmp/db/status", "/tmp/db/info"},
+		{Dpkg{StatusFileLocation: "/tmp/status", AdminDir: "/tmp/db"}, "/tmp/status", "/tmp/db/info"},
+	}
+
+	for _, test := range tests {
+		if got := test.d.statusFile(); got != test.statusFile {
+			t.Errorf("statusFile() of %+v = %s; want %s", test.d, got, test.statusFile)
+		}
+		if got := test.d.adminPath("info"); got != test.infoDir {
+			t.Errorf("adminPath(info) of %+v = %s; want %s", test.d, got, test.infoDir)
+		}
+	}
+}
@@ -87,24 +87,24 @@ func (ps *PackagesScanner) ScanPackages() ([]byte, error) {
 		}
 
 		if checkMultivalue(p, &pkgs) {
-			op := pkgs[p.Fields["Package"]]
+			op := pkgs[p.Get("Package")]
 
 			if ps.Multiversion {
-				fmt.Fprintf(os.Stderr, "multiversion enabled; adding repeated Package '%s'\n", p.Fields["Package"])
-				pkgs[p.Fields["Package"]+p.Fields["Version"]] = *p
+				fmt.Fprintf(os.Stderr, "multiversion enabled; adding repeated Package '%s'\n", p.Get("Package"))
+				pkgs[p.Get("Package")+p.Get("Version")] = *p
 				continue
 			}
 
-			if d.CompareVersions(p.Fields["Version"], op.Fields["Version"]) > 0 {
-				fmt.Fprintf(os.Stderr, "package '%s' (filename '%s') is repeat but newer version; ignored version '%s'!\n", p.Fields["Package"], p.Fields["Filename"], op.Fields["Version"])
-				pkgs[p.Fields["Package"]] = *p
+			if d.CompareVersions(p.Get("Version"), op.Get("Version")) > 0 {
+				fmt.Fprintf(os.Stderr, "package '%s' (filename '%s') is repeat but newer version; ignored version '%s'!\n", p.Get("Package"), p.Get("Filename"), op.Get("Version"))
+				pkgs[p.Get("Package")] = *p
 			} else {
-				fmt.Fprintf(os.Stderr, "package '%s' (filename '%s') is repeat but older; ignored version '%s'!\n", p.Fields["Package"], p.Fields["Filename"], p.Fields["Version"])
+				fmt.Fprintf(os.Stderr, "package '%s' (filename '%s') is repeat but older; ignored version '%s'!\n", p.Get("Package"), p.Get("Filename"), p.Get("Version"))
 				continue
 			}
 		}
 
-		pkgs[p.Fields["Package"]] = *p
+		pkgs[p.Get("Package")] = *p
 	}
 
 	return generatePackageIndex(&pkgs, &ps.Hashes), nil
@@ -154,7 +154,7 @@ func findFiles(directory string, filter *regexp.Regexp) ([]string, error) {
 
 // checkMultivalue checks if the package already exists in the map
 func checkMultivalue(p *DebPackage, pkgs *map[string]DebPackage) bool {
-	_, exists := (*pkgs)[p.Fields["Package"]]
+	_, exists := (*pkgs)[p.Get("Package")]
 	return exists
 }
 
@@ -183,9 +183,11 @@ func generatePackageIndex(pkgs *map[string]DebPackage, hashes *[]HASH) []byte {
 		"SHA1",
 	}
 
-	for _, p := range *pkgs {
-		printedFields := make(map[string]bool)
+	writer := NewParagraphWriter(&buffer)
+	writer.Order = priorityFields
+	writer.Terminate = true
 
+	for _, p := range *pkgs {
 		if containsHash(*hashes, "MD5") && containsHash(*hashes, "SHA1") && containsHash(*hashes, "SHA256") {
 			p.CalculateAllHashes()
 		}
@@ -200,22 +202,10 @@ func generatePackageIndex(pkgs *map[string]DebPackage, hashes *[]HASH) []byte {
 		}
 		p.CalcSize()
 
-		// Print priority fields first
-		for _, field := range priorityFields {
-			if value, exists := p.Fields[field]; exists {
-				buffer.WriteString(fmt.Sprintf("%s: %s\n", field, value))
-				printedFields[field] = true
-			}
+		// Print priority fields first, then the remaining fields
+		if err := writer.Write(&p.Paragraph); err != nil {
+			fmt.Fprintf(os.Stderr, "go-apt/dpkg: error writing package: '%s' - %v\n", p.Get("Filename"), err)
 		}
-
-		// Print remaining fields
-		for key, value := range p.Fields {
-			if !printedFields[key] {
-				buffer.WriteString(fmt.Sprintf("%s: %s\n", key, value))
-			}
-		}
-
-		buffer.WriteString("\n")
 	}
 	return buffer.Bytes()
 }
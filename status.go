@@ -0,0 +1,140 @@
+package dpkg
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Want represents the selection state of a package, the first word of the Status field
+type Want int
+
+const (
+	WantUnknown Want = iota
+	WantInstall
+	WantHold
+	WantDeinstall
+	WantPurge
+)
+
+// Flag represents the error flag of a package, the second word of the Status field
+type Flag int
+
+const (
+	FlagOK Flag = iota
+	FlagReinstReq
+)
+
+// State represents the installation state of a package, the third word of the Status field
+type State int
+
+const (
+	StateNotInstalled State = iota
+	StateConfigFiles
+	StateHalfInstalled
+	StateUnpacked
+	StateHalfConfigured
+	StateTriggersAwaited
+	StateTriggersPending
+	StateInstalled
+)
+
+// Names and dpkg -l abbreviations of the status words
+// https://salsa.debian.org/dpkg-team/dpkg/-/blob/main/lib/dpkg/parsehelp.c
+var (
+	wantNames  = []string{"unknown", "install", "hold", "deinstall", "purge"}
+	wantAbbrev = "uihrp"
+	flagNames  = []string{"ok", "reinstreq"}
+	flagAbbrev = " R"
+	stateNames = []string{
+		"not-installed",
+		"config-files",
+		"half-installed",
+		"unpacked",
+		"half-configured",
+		"triggers-awaited",
+		"triggers-pending",
+		"installed",
+	}
+	stateAbbrev = "ncHUFWti"
+)
+
+// String returns the name of the selection state
+func (w Want) String() string {
+	return enumName(wantNames, int(w))
+}
+
+// String returns the name of the error flag
+func (f Flag) String() string {
+	return enumName(flagNames, int(f))
+}
+
+// String returns the name of the installation state
+func (s State) String() string {
+	return enumName(stateNames, int(s))
+}
+
+// PackageStatus represents the parsed Status field of an installed package,
+// e.g. "install ok installed"
+type PackageStatus struct {
+	Want  Want
+	Flag  Flag
+	State State
+}
+
+// ParseStatus parses the value of the Status field
+func ParseStatus(s string) (PackageStatus, error) {
+	words := strings.Fields(s)
+	if len(words) != 3 {
+		return PackageStatus{}, fmt.Errorf("%w: Status %q must have three words", ErrInvalidField, s)
+	}
+
+	want, ok := enumIndex(wantNames, words[0])
+	if !ok {
+		return PackageStatus{}, fmt.Errorf("%w: Status %q has an unknown selection state", ErrInvalidField, s)
+	}
+	flag, ok := enumIndex(flagNames, words[1])
+	if !ok {
+		return PackageStatus{}, fmt.Errorf("%w: Status %q has an unknown error flag", ErrInvalidField, s)
+	}
+	state, ok := enumIndex(stateNames, words[2])
+	if !ok {
+		return PackageStatus{}, fmt.Errorf("%w: Status %q has an unknown installation state", ErrInvalidField, s)
+	}
+
+	return PackageStatus{Want: Want(want), Flag: Flag(flag), State: State(state)}, nil
+}
+
+// String returns the status in the format of the Status field
+func (ps PackageStatus) String() string {
+	return ps.Want.String() + " " + ps.Flag.String() + " " + ps.State.String()
+}
+
+// Abbrev returns the abbreviation of the status shown by dpkg -l, e.g. "ii", "rc"
+// or "iU", followed by "R" when the package requires reinstallation
+func (ps PackageStatus) Abbrev() string {
+	abbrev := statusAbbrev(wantAbbrev, int(ps.Want)) + statusAbbrev(stateAbbrev, int(ps.State)) + statusAbbrev(flagAbbrev, int(ps.Flag))
+	return strings.TrimRight(abbrev, " ")
+}
+
+// IsInstalled checks if the package is fully installed and configured
+func (ps PackageStatus) IsInstalled() bool {
+	return ps.State == StateInstalled
+}
+
+// IsConfigFiles checks if the package was removed but its configuration files remain
+func (ps PackageStatus) IsConfigFiles() bool {
+	return ps.State == StateConfigFiles
+}
+
+// Status returns the parsed Status field of the package
+func (dp *DebPackage) Status() (PackageStatus, error) {
+	return ParseStatus(dp.Get("Status"))
+}
+
+// statusAbbrev returns the i-th abbreviation, or "?" if it is out of range
+func statusAbbrev(abbrevs string, i int) string {
+	if i < 0 || i >= len(abbrevs) {
+		return "?"
+	}
+	return abbrevs[i : i+1]
+}
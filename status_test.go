@@ -0,0 +1,81 @@
+package dpkg
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+// TestParseStatus tests the ParseStatus function
+func TestParseStatus(t *testing.T) {
+	tests := []struct {
+		status     string
+		want       PackageStatus
+		wantAbbrev string
+	}{
+		{"install ok installed", PackageStatus{WantInstall, FlagOK, StateInstalled}, "ii"},
+		{"deinstall ok config-files", PackageStatus{WantDeinstall, FlagOK, StateConfigFiles}, "rc"},
+		{"hold reinstreq half-configured", PackageStatus{WantHold, FlagReinstReq, StateHalfConfigured}, "hFR"},
+		{"install ok unpacked", PackageStatus{WantInstall, FlagOK, StateUnpacked}, "iU"},
+		{"purge ok not-installed", PackageStatus{WantPurge, FlagOK, StateNotInstalled}, "pn"},
+		{"unknown ok half-installed", PackageStatus{WantUnknown, FlagOK, StateHalfInstalled}, "uH"},
+		{"install ok triggers-awaited", PackageStatus{WantInstall, FlagOK, StateTriggersAwaited}, "iW"},
+		{"install ok triggers-pending", PackageStatus{WantInstall, FlagOK, StateTriggersPending}, "it"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			got, err := ParseStatus(tt.status)
+			if err != nil {
+				t.Fatalf("ParseStatus(%q) unexpected error: %v", tt.status, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseStatus(%q) = %+v; want %+v", tt.status, got, tt.want)
+			}
+			if got.String() != tt.status {
+				t.Errorf("String() = %q; want %q", got.String(), tt.status)
+			}
+			if got.Abbrev() != tt.wantAbbrev {
+				t.Errorf("Abbrev() = %q; want %q", got.Abbrev(), tt.wantAbbrev)
+			}
+		})
+	}
+}
+
+// TestParseStatusErrors tests the errors reported by ParseStatus
+func TestParseStatusErrors(t *testing.T) {
+	for _, status := range []string{"", "install ok", "install ok installed now", "keep ok installed", "install bad installed", "install ok configured"} {
+		if _, err := ParseStatus(status); !errors.Is(err, ErrInvalidField) {
+			t.Errorf("ParseStatus(%q) error = %v; want ErrInvalidField", status, err)
+		}
+	}
+}
+
+// TestListInstalled tests the ListInstalled method of Dpkg
+func TestListInstalled(t *testing.T) {
+	d := &Dpkg{StatusFileLocation: "testdata/root/var/lib/dpkg/status"}
+
+	packages, err := d.ListInstalled()
+	if err != nil {
+		t.Fatalf("ListInstalled failed: %v", err)
+	}
+
+	var names []string
+	for _, pkg := range packages {
+		names = append(names, pkg.Name())
+	}
+	if want := []string{"adduser", "libc6"}; !slices.Equal(names, want) {
+		t.Errorf("ListInstalled() = %v; want %v", names, want)
+	}
+
+	packages, err = d.ListFunc(func(pkg *DebPackage) bool {
+		status, err := pkg.Status()
+		return err == nil && status.IsConfigFiles()
+	})
+	if err != nil {
+		t.Fatalf("ListFunc failed: %v", err)
+	}
+	if len(packages) != 1 || packages[0].Name() != "nano" {
+		t.Errorf("ListFunc(IsConfigFiles) = %v; want [nano]", packages)
+	}
+}
@@ -0,0 +1,148 @@
+package dpkg
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// WriteStatus replaces the status file of the dpkg database with the packages the way
+// dpkg does: they are written to status-new, which is synced to disk, the previous status
+// file is kept as status-old and status-new is renamed over it. The pending updates of the
+// journal, which List has applied to the packages, are then removed.
+func (l *DatabaseLock) WriteStatus(packages []DebPackage) error {
+	if l.files == nil {
+		return fmt.Errorf("go-apt/dpkg: the dpkg database is not locked")
+	}
+
+	statusFile := l.d.statusFile()
+	newFile, oldFile := statusFile+"-new", statusFile+"-old"
+
+	if err := writeStatusFile(newFile, packages); err != nil {
+		os.Remove(newFile)
+		return err
+	}
+
+	// status-old is a hardlink to the previous status file, if any
+	if err := os.Remove(oldFile); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if err := os.Link(statusFile, oldFile); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if err := os.Rename(newFile, statusFile); err != nil {
+		return err
+	}
+	if err := syncDir(filepath.Dir(statusFile)); err != nil {
+		return err
+	}
+
+	updates, err := l.d.pendingUpdates()
+	if err != nil || len(updates) == 0 {
+		return err
+	}
+	for _, update := range updates {
+		if err := os.Remove(update); err != nil {
+			return err
+		}
+	}
+	return syncDir(filepath.Dir(updates[0]))
+}
+
+// UpdateStatus locks the dpkg database, passes the packages of the status file to update
+// and writes the packages it returns with WriteStatus
+func (d *Dpkg) UpdateStatus(update func(packages []DebPackage) ([]DebPackage, error)) (err error) {
+	lock, err := d.Lock()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Join(err, lock.Unlock())
+	}()
+
+	// The pending updates are folded into the new status file
+	status := *d
+	status.Database = DatabaseStatus
+	packages, err := status.List()
+	if err != nil {
+		return err
+	}
+
+	if packages, err = update(packages); err != nil {
+		return err
+	}
+	return lock.WriteStatus(packages)
+}
+
+// SetSelection sets the selection state of a package of the status file, like
+// dpkg --set-selections. The name can be qualified by an architecture.
+func (d *Dpkg) SetSelection(pkgName string, want Want) error {
+	return d.UpdateStatus(func(packages []DebPackage) ([]DebPackage, error) {
+		name, arch, qualified := strings.Cut(pkgName, ":")
+
+		found := -1
+		for i := range packages {
+			if packages[i].Name() != name || (qualified && packages[i].Architecture() != arch) {
+				continue
+			}
+			if found >= 0 {
+				return nil, fmt.Errorf("%w: %s is installed for several architectures", ErrAmbiguousPackage, pkgName)
+			}
+			found = i
+		}
+		if found < 0 {
+			return nil, fmt.Errorf("%w: %s", ErrPackageNotInstalled, pkgName)
+		}
+
+		status, err := packages[found].Status()
+		if err != nil {
+			return nil, err
+		}
+		status.Want = want
+		packages[found].Set("Status", status.String())
+
+		return packages, nil
+	})
+}
+
+// writeStatusFile writes the packages to a new file and syncs it to disk
+func writeStatusFile(name string, packages []DebPackage) error {
+	file, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(file)
+	pw := NewParagraphWriter(w)
+	pw.Terminate = true
+	for i := range packages {
+		if err := pw.Write(&packages[i].Paragraph); err != nil {
+			file.Close()
+			return err
+		}
+	}
+
+	err = w.Flush()
+	if err == nil {
+		// The mode does not depend on the umask, as with dpkg
+		err = file.Chmod(0644)
+	}
+	if err == nil {
+		err = file.Sync()
+	}
+	return errors.Join(err, file.Close())
+}
+
+// syncDir syncs a directory to disk, so that the renames and removals in it are durable
+func syncDir(dir string) error {
+	file, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	err = file.Sync()
+	return errors.Join(err, file.Close())
+}
@@ -0,0 +1,92 @@
+//go:build unix
+
+package dpkg
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestSetSelection tests that the status file is replaced with the pending updates and
+// the new selection, keeping the previous one as status-old
+func TestSetSelection(t *testing.T) {
+	root := newTestRoot(t)
+	adminDir := filepath.Join(root, "var/lib/dpkg")
+	original, err := os.ReadFile(filepath.Join(adminDir, "status"))
+	if err != nil {
+		t.Fatalf("Failed to read the status file: %v", err)
+	}
+
+	// Pending update of curl, which is configured
+	start := strings.Index(string(original), "Package: curl\n")
+	curl := strings.Replace(string(original[start:]), "install ok unpacked", "install ok installed", 1)
+	if err := os.MkdirAll(filepath.Join(adminDir, "updates"), 0755); err != nil {
+		t.Fatalf("Failed to create updates directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(adminDir, "updates", "0000"), []byte(curl), 0644); err != nil {
+		t.Fatalf("Failed to write update: %v", err)
+	}
+
+	d := NewDpkg()
+	d.Root = root
+	if err := d.SetSelection("curl:amd64", WantHold); err != nil {
+		t.Fatalf("SetSelection failed: %v", err)
+	}
+
+	// The stanzas are written back unchanged, except for the status of curl
+	status, err := os.ReadFile(filepath.Join(adminDir, "status"))
+	if err != nil {
+		t.Fatalf("Failed to read the status file: %v", err)
+	}
+	want := string(original[:start]) + strings.Replace(curl, "install ok installed", "hold ok installed", 1)
+	if string(status) != want {
+		t.Errorf("status =\n%s\nwant\n%s", status, want)
+	}
+
+	if old, err := os.ReadFile(filepath.Join(adminDir, "status-old")); err != nil || string(old) != string(original) {
+		t.Errorf("status-old differs from the previous status file: %v", err)
+	}
+	for _, name := range []string{"status-new", "updates/0000"} {
+		if _, err := os.Stat(filepath.Join(adminDir, name)); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("%s was not removed: %v", name, err)
+		}
+	}
+	if info, err := os.Stat(filepath.Join(adminDir, "status")); err != nil || info.Mode().Perm() != 0644 {
+		t.Errorf("Stat(status) = %v, %v; want mode 0644", info, err)
+	}
+
+	tests := []struct {
+		pkgName string
+		err     error
+	}{
+		{"missing", ErrPackageNotInstalled},
+		{"curl:i386", ErrPackageNotInstalled},
+	}
+	for _, test := range tests {
+		if err := d.SetSelection(test.pkgName, WantPurge); !errors.Is(err, test.err) {
+			t.Errorf("SetSelection(%s) error = %v; want %v", test.pkgName, err, test.err)
+		}
+	}
+	if after, err := os.ReadFile(filepath.Join(adminDir, "status")); err != nil || string(after) != string(status) {
+		t.Errorf("status changed by failed updates: %v", err)
+	}
+}
+
+// TestWriteStatusUnlocked tests that the status file cannot be written after unlocking
+func TestWriteStatusUnlocked(t *testing.T) {
+	d := Dpkg{AdminDir: t.TempDir()}
+	lock, err := d.Lock()
+	if err != nil {
+		t.Fatalf("Lock failed: %v", err)
+	}
+	if err := lock.Unlock(); err != nil {
+		t.Fatalf("Unlock failed: %v", err)
+	}
+	if err := lock.WriteStatus(nil); err == nil {
+		t.Errorf("Expected an error writing the status file without the lock")
+	}
+}
@@ -0,0 +1,281 @@
+package dpkg
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Severity represents the severity of a validation finding
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityError
+)
+
+var severityNames = []string{"info", "warning", "error"}
+
+// String returns the name of the severity
+func (s Severity) String() string {
+	return enumName(severityNames, int(s))
+}
+
+// Finding represents a problem found when validating a package
+type Finding struct {
+	Severity Severity
+	Member   string // Name of the ar member concerned, if any
+	Message  string
+}
+
+// String returns the finding in the format "severity: member: message"
+func (f Finding) String() string {
+	if f.Member == "" {
+		return f.Severity.String() + ": " + f.Message
+	}
+	return f.Severity.String() + ": " + f.Member + ": " + f.Message
+}
+
+// arHeaderSize is the size of the header of an ar member
+const arHeaderSize = 60
+
+// maxDebianBinarySize is the size up to which the debian-binary member is read, which is
+// enough for any format version
+const maxDebianBinarySize = 16
+
+// Allowed compression extensions of the control and data members
+// https://manpages.debian.org/unstable/dpkg-dev/deb.5.en.html
+var (
+	controlExtensions = []string{"", ".gz", ".xz", ".zst"}
+	dataExtensions    = []string{"", ".gz", ".xz", ".zst", ".bz2", ".lzma"}
+)
+
+// ValidateDeb checks the structure of a Debian package against deb(5): the ar headers, the
+// debian-binary version, the names and order of the members, the readability of the
+// control and data archives, and the mandatory control fields. The error is only set when
+// the file cannot be read; problems of the package are returned as findings.
+func (d *Dpkg) ValidateDeb(debFile string) ([]Finding, error) {
+	file, err := os.Open(debFile)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	a, err := OpenDeb(file, info.Size())
+	if err != nil {
+		return []Finding{{Severity: SeverityError, Message: err.Error()}}, nil
+	}
+	return a.Validate(), nil
+}
+
+// Validate checks the structure of the package, see Dpkg.ValidateDeb
+func (a *DebArchive) Validate() []Finding {
+	v := &validator{a: a}
+	v.checkHeaders()
+	v.checkMembers()
+	v.checkControl()
+	v.checkData()
+	return v.findings
+}
+
+// validator collects the findings of a validation
+type validator struct {
+	a        *DebArchive
+	findings []Finding
+}
+
+// add adds a finding
+func (v *validator) add(severity Severity, member, format string, args ...any) {
+	v.findings = append(v.findings, Finding{Severity: severity, Member: member, Message: fmt.Sprintf(format, args...)})
+}
+
+// checkHeaders checks the fields of the ar headers, which the ar reader parses leniently
+func (v *validator) checkHeaders() {
+	header := make([]byte, arHeaderSize)
+	for _, member := range v.a.members {
+		if _, err := v.a.r.ReadAt(header, member.offset-arHeaderSize); err != nil {
+			v.add(SeverityError, member.name, "failed to read ar header: %v", err)
+			continue
+		}
+
+		fields := []struct {
+			name  string
+			value []byte
+			base  int
+		}{
+			{"modification time", header[16:28], 10},
+			{"owner", header[28:34], 10},
+			{"group", header[34:40], 10},
+			{"mode", header[40:48], 8},
+			{"size", header[48:58], 10},
+		}
+		for _, field := range fields {
+			value := strings.TrimRight(string(field.value), " ")
+			if _, err := strconv.ParseUint(value, field.base, 64); err != nil {
+				v.add(SeverityError, member.name, "invalid ar header %s %q", field.name, value)
+			}
+		}
+		if string(header[58:60]) != "`\n" {
+			v.add(SeverityError, member.name, "invalid ar header terminator %q", header[58:60])
+		}
+	}
+}
+
+// checkMembers checks the debian-binary member, and the names and order of the members:
+// debian-binary, control.tar and data.tar must come first, in that order, apart from the
+// members starting with an underscore, which may appear anywhere
+func (v *validator) checkMembers() {
+	members := v.a.members
+	if len(members) == 0 || members[0].name != "debian-binary" {
+		v.add(SeverityError, "", "the first member must be debian-binary")
+	} else if members[0].size > maxDebianBinarySize {
+		v.add(SeverityError, "debian-binary", "too large, %d bytes", members[0].size)
+	} else {
+		content := make([]byte, members[0].size)
+		if _, err := v.a.r.ReadAt(content, members[0].offset); err != nil {
+			v.add(SeverityError, "debian-binary", "failed to read: %v", err)
+		} else if version := strings.TrimSuffix(string(content), "\n"); version != "2.0" {
+			severity := SeverityError
+			// Later minor versions are accepted by dpkg
+			if strings.HasPrefix(version, "2.") && isDigits(version[2:]) && strings.HasSuffix(string(content), "\n") {
+				severity = SeverityWarning
+			}
+			v.add(severity, "debian-binary", "unsupported format version %q, expected 2.0", version)
+		}
+	}
+
+	var controls, datas int
+	position := -1 // Position of the member, not counting the members starting with "_"
+	for i, member := range members {
+		if !strings.HasPrefix(member.name, "_") {
+			position++
+		}
+		if i == 0 && member.name == "debian-binary" {
+			continue
+		}
+
+		switch {
+		case member.name == "debian-binary":
+			v.add(SeverityError, member.name, "duplicate member")
+		case strings.HasPrefix(member.name, "control.tar"):
+			controls++
+			if !hasExtension(member.name, "control.tar", controlExtensions) {
+				v.add(SeverityError, member.name, "unsupported compression of the control archive")
+			}
+			if position != 1 {
+				v.add(SeverityError, member.name, "the control archive must be the second member")
+			}
+		case strings.HasPrefix(member.name, "data.tar"):
+			datas++
+			if !hasExtension(member.name, "data.tar", dataExtensions) {
+				v.add(SeverityError, member.name, "unsupported compression of the data archive")
+			}
+			if position != 2 {
+				v.add(SeverityError, member.name, "the data archive must be the third member")
+			}
+		case strings.HasPrefix(member.name, "_"):
+			// Members starting with an underscore are reserved for local additions
+			v.add(SeverityInfo, member.name, "ignored member")
+		case datas > 0:
+			// Members after the data archive are ignored, as deb(5) requires
+			v.add(SeverityWarning, member.name, "unknown member")
+		default:
+			v.add(SeverityError, member.name, "unknown member")
+		}
+	}
+
+	if controls != 1 {
+		v.add(SeverityError, "", "expected exactly one control archive, found %d", controls)
+	}
+	if datas != 1 {
+		v.add(SeverityError, "", "expected exactly one data archive, found %d", datas)
+	}
+}
+
+// checkControl checks that the control archive is readable and its mandatory fields
+func (v *validator) checkControl() {
+	member, ok := v.a.member("control.tar")
+	if !ok {
+		return
+	}
+
+	control, err := v.a.Control()
+	if err != nil {
+		v.add(SeverityError, member.name, "%v", err)
+		return
+	}
+	pkg := control.Control
+
+	for _, name := range []string{"Package", "Version", "Architecture", "Maintainer", "Description"} {
+		if pkg.Get(name) == "" {
+			// dpkg-deb only warns about the missing descriptive fields
+			severity := SeverityError
+			if name == "Maintainer" || name == "Description" {
+				severity = SeverityWarning
+			}
+			v.add(severity, member.name, "missing mandatory field %s", name)
+		}
+	}
+
+	if name := pkg.Get("Package"); name != "" && !isValidPackageName(name) {
+		v.add(SeverityError, member.name, "invalid package name %q", name)
+	}
+	if version := pkg.Get("Version"); version != "" {
+		if _, err := ParseVersion(version); err != nil {
+			v.add(SeverityError, member.name, "%v", err)
+		}
+	}
+	if arch := pkg.Get("Architecture"); arch != "" && !isValidArchName(arch) {
+		v.add(SeverityError, member.name, "invalid architecture %q", arch)
+	}
+	if _, err := pkg.InstalledSize(); err != nil {
+		v.add(SeverityWarning, member.name, "%v", err)
+	}
+}
+
+// checkData checks that the data archive is readable to the end
+func (v *validator) checkData() {
+	member, ok := v.a.member("data.tar")
+	if !ok {
+		return
+	}
+
+	uncompressedData, err := v.a.Data()
+	if err != nil {
+		v.add(SeverityError, member.name, "%v", err)
+		return
+	}
+	defer uncompressedData.Close()
+
+	err = walkDataArchive(uncompressedData, func(header *tar.Header, r io.Reader) error {
+		if _, err := cleanArchivePath(header.Name); err != nil {
+			v.add(SeverityError, member.name, "%v", err)
+		}
+		// The content is read to detect corrupted compressed streams
+		_, err := io.Copy(io.Discard, r)
+		return err
+	})
+	if err != nil {
+		v.add(SeverityError, member.name, "%v", err)
+	}
+}
+
+// hasExtension checks if name is prefix followed by one of the extensions
+func hasExtension(name, prefix string, extensions []string) bool {
+	return slices.ContainsFunc(extensions, func(extension string) bool { return name == prefix+extension })
+}
+
+// HasErrors checks if any of the findings is an error
+func HasErrors(findings []Finding) bool {
+	return slices.ContainsFunc(findings, func(f Finding) bool { return f.Severity == SeverityError })
+}
@@ -0,0 +1,229 @@
+package dpkg
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blakesmith/ar"
+)
+
+// buildAr creates an ar archive from the given members
+func buildAr(t *testing.T, members [][2]string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	writer := ar.NewWriter(&buf)
+	if err := writer.WriteGlobalHeader(); err != nil {
+		t.Fatalf("Failed to write ar header: %v", err)
+	}
+	for _, member := range members {
+		header := &ar.Header{Name: member[0], ModTime: time.Unix(0, 0), Mode: 0644, Size: int64(len(member[1]))}
+		if err := writer.WriteHeader(header); err != nil {
+			t.Fatalf("Failed to write ar header: %v", err)
+		}
+		if _, err := writer.Write([]byte(member[1])); err != nil {
+			t.Fatalf("Failed to write ar member: %v", err)
+		}
+	}
+	return buf.Bytes()
+}
+
+// buildTestMembers builds the test package and returns the content of its control and data members
+func buildTestMembers(t *testing.T, b *Builder) (control, data string) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := b.Build(&buf); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	a, err := OpenDeb(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("OpenDeb failed: %v", err)
+	}
+
+	content := make([]string, len(a.members))
+	for i, member := range a.members {
+		raw := make([]byte, member.size)
+		if _, err := a.r.ReadAt(raw, member.offset); err != nil {
+			t.Fatalf("Failed to read member %s: %v", member.name, err)
+		}
+		content[i] = string(raw)
+	}
+	return content[1], content[2]
+}
+
+// TestValidateDeb tests that valid packages have no findings
+func TestValidateDeb(t *testing.T) {
+	for _, filePath := range []string{"testdata/debs/vim-tiny_9.1.1113-1_amd64.deb", "testdata/debs/vim-tiny_7.0-122+1etch5_amd64.deb"} {
+		d := Dpkg{}
+		findings, err := d.ValidateDeb(filePath)
+		if err != nil {
+			t.Fatalf("ValidateDeb(%s) failed: %v", filePath, err)
+		}
+		if len(findings) != 0 {
+			t.Errorf("ValidateDeb(%s) = %v; want no findings", filePath, findings)
+		}
+	}
+}
+
+// TestValidateDebFindings tests the findings reported for invalid packages
+func TestValidateDebFindings(t *testing.T) {
+	b := newTestBuilder()
+	b.Compression = CompressionGzip
+	control, data := buildTestMembers(t, b)
+
+	b = newTestBuilder()
+	b.Control.Delete("Maintainer")
+	b.Control.Set("Version", "1.0 beta")
+	b.Compression = CompressionGzip
+	badControl, _ := buildTestMembers(t, b)
+
+	tests := []struct {
+		name    string
+		members [][2]string
+		want    []string
+	}{
+		{
+			"valid",
+			[][2]string{{"debian-binary", "2.0\n"}, {"control.tar.gz", control}, {"data.tar.gz", data}, {"_gpgorigin", "signature"}},
+			[]string{"info: _gpgorigin: ignored member"},
+		},
+		{
+			"unknown member after the data archive",
+			[][2]string{{"debian-binary", "2.0\n"}, {"control.tar.gz", control}, {"data.tar.gz", data}, {"signature", ""}},
+			[]string{"warning: signature: unknown member"},
+		},
+		{
+			"newer format",
+			[][2]string{{"debian-binary", "2.1\n"}, {"control.tar.gz", control}, {"data.tar.gz", data}},
+			[]string{`warning: debian-binary: unsupported format version "2.1", expected 2.0`},
+		},
+		{
+			"invalid structure",
+			[][2]string{{"debian-binary", "3.0\n"}, {"data.tar.gz", data}, {"control.tar.gz", control}, {"control.tar.lz4", control}, {"extra", ""}},
+			[]string{
+				`error: debian-binary: unsupported format version "3.0", expected 2.0`,
+				"error: data.tar.gz: the data archive must be the third member",
+				"error: control.tar.gz: the control archive must be the second member",
+				"error: control.tar.lz4: unsupported compression of the control archive",
+				"error: control.tar.lz4: the control archive must be the second member",
+				"warning: extra: unknown member",
+				"error: expected exactly one control archive, found 2",
+			},
+		},
+		{
+			"ignored member before the control archive",
+			[][2]string{{"debian-binary", "2.0\n"}, {"_foo", ""}, {"control.tar.gz", control}, {"data.tar.gz", data}},
+			[]string{"info: _foo: ignored member"},
+		},
+		{
+			"unknown member before the control archive",
+			[][2]string{{"debian-binary", "2.0\n"}, {"foo", ""}, {"control.tar.gz", control}, {"data.tar.gz", data}},
+			[]string{
+				"error: foo: unknown member",
+				"error: control.tar.gz: the control archive must be the second member",
+				"error: data.tar.gz: the data archive must be the third member",
+			},
+		},
+		{
+			"oversized debian-binary",
+			[][2]string{{"debian-binary", strings.Repeat("2.0\n", 1000)}, {"control.tar.gz", control}, {"data.tar.gz", data}},
+			[]string{"error: debian-binary: too large, 4000 bytes"},
+		},
+		{
+			"missing members",
+			[][2]string{{"control.tar.gz", control}},
+			[]string{
+				"error: the first member must be debian-binary",
+				"error: control.tar.gz: the control archive must be the second member",
+				"error: expected exactly one data archive, found 0",
+			},
+		},
+		{
+			"invalid control",
+			[][2]string{{"debian-binary", "2.0\n"}, {"control.tar.gz", badControl}, {"data.tar.gz", "corrupted"}},
+			[]string{
+				"warning: control.tar.gz: missing mandatory field Maintainer",
+				`error: control.tar.gz: go-apt/dpkg: invalid version "1.0 beta": version string has embedded spaces`,
+				"error: data.tar.gz: go-apt/dpkg: unsupported compression format for data.tar.gz",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			debFile := filepath.Join(t.TempDir(), "test.deb")
+			if err := os.WriteFile(debFile, buildAr(t, test.members), 0644); err != nil {
+				t.Fatalf("Failed to write package: %v", err)
+			}
+
+			d := Dpkg{}
+			findings, err := d.ValidateDeb(debFile)
+			if err != nil {
+				t.Fatalf("ValidateDeb failed: %v", err)
+			}
+
+			var got []string
+			for _, finding := range findings {
+				got = append(got, finding.String())
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("ValidateDeb() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(test.want, "\n"))
+			}
+		})
+	}
+}
+
+// TestValidateDebHeaders tests the findings reported for broken ar archives
+func TestValidateDebHeaders(t *testing.T) {
+	valid := buildAr(t, [][2]string{{"debian-binary", "2.0\n"}})
+
+	broken := bytes.Clone(valid)
+	copy(broken[8+28:], "root  ") // Owner field
+	broken[8+58] = 'x'            // Terminator
+
+	d := Dpkg{}
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"not an ar archive", []byte("PK\x03\x04"), ErrDebHeader.Error()},
+		{"truncated", valid[:len(valid)-2], "truncated ar member debian-binary"},
+		{"invalid owner", broken, `invalid ar header owner "root"`},
+		{"invalid terminator", broken, "invalid ar header terminator"},
+	}
+
+	for _, test := range tests {
+		debFile := filepath.Join(t.TempDir(), "test.deb")
+		if err := os.WriteFile(debFile, test.data, 0644); err != nil {
+			t.Fatalf("Failed to write package: %v", err)
+		}
+
+		findings, err := d.ValidateDeb(debFile)
+		if err != nil {
+			t.Fatalf("ValidateDeb failed: %v", err)
+		}
+		if !HasErrors(findings) || !strings.Contains(findingsString(findings), test.want) {
+			t.Errorf("%s: ValidateDeb() = %v; want %q", test.name, findings, test.want)
+		}
+	}
+
+	if _, err := d.ValidateDeb("testdata/debs/missing.deb"); err == nil {
+		t.Errorf("Expected an error for a missing file")
+	}
+}
+
+// findingsString joins the findings, one per line
+func findingsString(findings []Finding) string {
+	var lines []string
+	for _, finding := range findings {
+		lines = append(lines, finding.String())
+	}
+	return strings.Join(lines, "\n")
+}
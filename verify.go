@@ -0,0 +1,271 @@
+package dpkg
+
+import (
+	"archive/tar"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+)
+
+// VerifyStatus represents the result of the verification of a file
+type VerifyStatus int
+
+const (
+	VerifyOK         VerifyStatus = iota
+	VerifyMismatch                // The digest differs from the one of the md5sums file
+	VerifyMissing                 // The file is listed in the md5sums file but not shipped
+	VerifyExtra                   // The file is shipped but not listed in the md5sums file
+	VerifyUnverified              // The file is a conffile without digest to compare with
+)
+
+var verifyStatusNames = []string{"ok", "mismatch", "missing", "extra", "unverified"}
+
+// String returns the name of the verification result
+func (s VerifyStatus) String() string {
+	return enumName(verifyStatusNames, int(s))
+}
+
+// VerifiedFile represents the verification of a single file
+type VerifiedFile struct {
+	Path     string // Path relative to the root directory, as in md5sums files
+	Status   VerifyStatus
+	Expected string // Digest of the md5sums file
+	Actual   string // Digest of the content
+	Conffile bool   // The file is a conffile, verified against the dpkg database
+	Err      error  // Error reading an installed file, other than it not existing
+}
+
+// String returns the verification result of mismatching and missing files in the
+// output format of dpkg --verify, e.g. "??5?????? c /etc/hello.conf", where only the
+// digest is checked, and the other results as the status and the path, e.g.
+// "ok /usr/bin/hello"
+func (f VerifiedFile) String() string {
+	var line string
+	switch f.Status {
+	case VerifyMismatch, VerifyMissing:
+		result := "??5??????"
+		if f.Status == VerifyMissing {
+			result = "missing  "
+		}
+		attr := ' '
+		if f.Conffile {
+			attr = 'c'
+		}
+		line = fmt.Sprintf("%s %c /%s", result, attr, f.Path)
+	default:
+		line = fmt.Sprintf("%s /%s", f.Status, f.Path)
+	}
+
+	if f.Err != nil {
+		line += " (" + f.Err.Error() + ")"
+	}
+	return line
+}
+
+// VerifyReport represents the verification of the files of a package
+type VerifyReport struct {
+	Files []VerifiedFile
+}
+
+// OK checks if every file was verified successfully
+func (r *VerifyReport) OK() bool {
+	return len(r.Problems()) == 0
+}
+
+// Problems returns the files which were not verified successfully; unverified
+// conffiles are not problems
+func (r *VerifyReport) Problems() []VerifiedFile {
+	var problems []VerifiedFile
+	for _, file := range r.Files {
+		if file.Status != VerifyOK && file.Status != VerifyUnverified {
+			problems = append(problems, file)
+		}
+	}
+	return problems
+}
+
+// VerifyDeb hashes every regular file of the data archive of a Debian package and
+// compares it with the md5sums control member; conffiles, which are usually not listed
+// in md5sums files, are reported as unverified rather than as extra files
+func (d *Dpkg) VerifyDeb(debFile string) (*VerifyReport, error) {
+	var report *VerifyReport
+
+	err := openDebFile(debFile, func(a *DebArchive) error {
+		var err error
+		report, err = a.Verify()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// Verify verifies the data archive of the package, see Dpkg.VerifyDeb
+func (a *DebArchive) Verify() (*VerifyReport, error) {
+	control, err := a.Control()
+	if err != nil {
+		return nil, err
+	}
+	if control.MD5Sums == nil {
+		return nil, ErrNoMD5Sums
+	}
+
+	conffiles := make(map[string]bool)
+	for _, conffile := range control.Conffiles {
+		conffiles[strings.TrimPrefix(conffile.Path, "/")] = true
+	}
+
+	uncompressedData, err := a.Data()
+	if err != nil {
+		return nil, err
+	}
+	defer uncompressedData.Close()
+
+	report := &VerifyReport{}
+	digests := make(map[string]string)
+	seen := make(map[string]bool)
+	err = walkDataArchive(uncompressedData, func(header *tar.Header, r io.Reader) error {
+		name, err := cleanArchivePath(header.Name)
+		if err != nil {
+			return err
+		}
+
+		var actual string
+		switch header.Typeflag {
+		case tar.TypeReg, tar.TypeRegA:
+			hash := md5.New()
+			if _, err := io.Copy(hash, r); err != nil {
+				return err
+			}
+			actual = hex.EncodeToString(hash.Sum(nil))
+			digests[name] = actual
+		case tar.TypeLink:
+			// Hardlinks share the content of a previous file
+			target, err := cleanArchivePath(header.Linkname)
+			if err != nil {
+				return err
+			}
+			actual = digests[target]
+		default:
+			return nil
+		}
+
+		seen[name] = true
+		expected, listed := control.MD5Sums[name]
+		switch {
+		case !listed && conffiles[name]:
+			report.Files = append(report.Files, VerifiedFile{Path: name, Status: VerifyUnverified, Actual: actual, Conffile: true})
+		case !listed:
+			report.Files = append(report.Files, VerifiedFile{Path: name, Status: VerifyExtra, Actual: actual})
+		case expected != actual:
+			report.Files = append(report.Files, VerifiedFile{Path: name, Status: VerifyMismatch, Expected: expected, Actual: actual, Conffile: conffiles[name]})
+		default:
+			report.Files = append(report.Files, VerifiedFile{Path: name, Status: VerifyOK, Expected: expected, Actual: actual, Conffile: conffiles[name]})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var missing []string
+	for name := range control.MD5Sums {
+		if !seen[name] {
+			missing = append(missing, name)
+		}
+	}
+	slices.Sort(missing)
+	for _, name := range missing {
+		report.Files = append(report.Files, VerifiedFile{Path: name, Status: VerifyMissing, Expected: control.MD5Sums[name]})
+	}
+
+	return report, nil
+}
+
+// Verify hashes the installed files of a package and compares them with its
+// info/<pkg>.md5sums file, and its conffiles with the digests of the Conffiles field of
+// the dpkg database, like dpkg --verify. The files are read below Root, following
+// symlinks relative to it.
+func (d *Dpkg) Verify(pkgName string) (*VerifyReport, error) {
+	pkg, err := d.installedPackage(pkgName)
+	if err != nil {
+		return nil, err
+	}
+	return d.VerifyPackage(pkg)
+}
+
+// VerifyPackage verifies an installed package of the dpkg database like Verify, e.g. one
+// returned by Packages, without looking it up again
+func (d *Dpkg) VerifyPackage(pkg *DebPackage) (*VerifyReport, error) {
+	// Packages without md5sums file only have their conffiles verified
+	digests := make(map[string]string)
+	file, err := os.Open(d.adminPath("info", infoName(pkg)+".md5sums"))
+	if err == nil {
+		digests, err = ParseMD5Sums(file)
+		file.Close()
+	}
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	conffiles, err := pkg.Conffiles()
+	if err != nil {
+		return nil, err
+	}
+	isConffile := make(map[string]bool)
+	for _, conffile := range conffiles {
+		// Conffiles of packages which were never configured have no digest yet
+		if conffile.Hash == "newconffile" {
+			continue
+		}
+		name := strings.TrimPrefix(conffile.Path, "/")
+		digests[name] = strings.ToLower(conffile.Hash)
+		isConffile[name] = true
+	}
+
+	report := &VerifyReport{}
+	for _, name := range slices.Sorted(maps.Keys(digests)) {
+		file := VerifiedFile{Path: name, Expected: digests[name], Conffile: isConffile[name]}
+
+		filePath, err := resolveInRoot(d.root(), name)
+		if err == nil {
+			_, err = os.Lstat(filePath)
+		}
+		if err != nil {
+			file.Status = VerifyMissing
+			if !errors.Is(err, fs.ErrNotExist) {
+				file.Err = err
+			}
+		} else if file.Actual, file.Err = hashFile(filePath); file.Err != nil || file.Actual != file.Expected {
+			file.Status = VerifyMismatch
+		}
+
+		report.Files = append(report.Files, file)
+	}
+
+	return report, nil
+}
+
+// hashFile returns the hexadecimal md5 digest of the content of a file
+func hashFile(filePath string) (string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := md5.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
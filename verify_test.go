@@ -0,0 +1,175 @@
+package dpkg
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+// TestVerifyDeb tests the VerifyDeb method of Dpkg
+func TestVerifyDeb(t *testing.T) {
+	for _, filePath := range []string{"testdata/debs/vim-tiny_9.1.1113-1_amd64.deb", "testdata/debs/vim-tiny_7.0-122+1etch5_amd64.deb"} {
+		d := Dpkg{}
+		report, err := d.VerifyDeb(filePath)
+		if err != nil {
+			t.Fatalf("VerifyDeb(%s) failed: %v", filePath, err)
+		}
+		if !report.OK() || len(report.Files) == 0 {
+			t.Errorf("VerifyDeb(%s) problems: %+v", filePath, report.Problems())
+		}
+	}
+}
+
+// TestVerifyTampered tests the report of a package whose files do not match its md5sums
+func TestVerifyTampered(t *testing.T) {
+	b := newTestBuilder()
+	b.ControlFiles = append(b.ControlFiles, ControlFile{Name: "md5sums", Mode: 0644, Data: []byte(
+		"00000000000000000000000000000000  usr/bin/hello\n" +
+			"00000000000000000000000000000000  usr/bin/hi\n" +
+			"d41d8cd98f00b204e9800998ecf8427e  usr/share/doc/hello/copyright\n")})
+	b.Files = append(b.Files, BuildFile{DataEntry: DataEntry{Path: "usr/bin/extra", Type: EntryRegular, Mode: 0755}})
+
+	var buf bytes.Buffer
+	if err := b.Build(&buf); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	a, err := OpenDeb(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("OpenDeb failed: %v", err)
+	}
+
+	report, err := a.Verify()
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+
+	var got []string
+	for _, file := range report.Problems() {
+		got = append(got, file.Status.String()+" "+file.Path)
+	}
+	// The conffile etc/hello.conf is not listed in md5sums and is not a problem
+	want := []string{
+		"extra usr/bin/extra",
+		"mismatch usr/bin/hello",
+		"mismatch usr/bin/hi",
+		"missing usr/share/doc/hello/copyright",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Problems() = %v; want %v", got, want)
+	}
+	if report.OK() {
+		t.Errorf("OK() = true for a tampered package")
+	}
+
+	// The conffile is reported as unverified, with the digest of its content
+	sum := md5.Sum(bytes.Repeat([]byte("x"), 2000))
+	conffile := VerifiedFile{Path: "etc/hello.conf", Status: VerifyUnverified, Actual: hex.EncodeToString(sum[:]), Conffile: true}
+	if i := slices.IndexFunc(report.Files, func(file VerifiedFile) bool { return file.Path == conffile.Path }); i < 0 || report.Files[i] != conffile {
+		t.Errorf("Files do not contain %+v", conffile)
+	}
+}
+
+// TestVerifiedFileString tests the output of the verification results
+func TestVerifiedFileString(t *testing.T) {
+	tests := []struct {
+		file VerifiedFile
+		want string
+	}{
+		{VerifiedFile{Path: "usr/bin/hello", Status: VerifyOK}, "ok /usr/bin/hello"},
+		{VerifiedFile{Path: "usr/bin/extra", Status: VerifyExtra}, "extra /usr/bin/extra"},
+		{VerifiedFile{Path: "etc/hello.conf", Status: VerifyUnverified, Conffile: true}, "unverified /etc/hello.conf"},
+		{VerifiedFile{Path: "etc/hello.conf", Status: VerifyMismatch, Conffile: true}, "??5?????? c /etc/hello.conf"},
+		{VerifiedFile{Path: "usr/bin/hello", Status: VerifyMismatch}, "??5??????   /usr/bin/hello"},
+		{VerifiedFile{Path: "usr/bin/hello", Status: VerifyMissing, Err: fs.ErrPermission}, "missing     /usr/bin/hello (permission denied)"},
+	}
+	for _, test := range tests {
+		if got := test.file.String(); got != test.want {
+			t.Errorf("String() = %q; want %q", got, test.want)
+		}
+	}
+}
+
+// TestVerifyNoMD5Sums tests that packages without md5sums are reported
+func TestVerifyNoMD5Sums(t *testing.T) {
+	b := newTestBuilder()
+	b.Files = nil
+
+	var buf bytes.Buffer
+	if err := b.Build(&buf); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	a, err := OpenDeb(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("OpenDeb failed: %v", err)
+	}
+
+	if _, err := a.Verify(); !errors.Is(err, ErrNoMD5Sums) {
+		t.Errorf("Verify error = %v; want ErrNoMD5Sums", err)
+	}
+}
+
+// TestVerify tests the verification of installed packages against the dpkg database
+func TestVerify(t *testing.T) {
+	root := newTestRoot(t)
+	d := Dpkg{StatusFileLocation: filepath.Join(root, "var/lib/dpkg/status"), Root: root}
+
+	for _, pkgName := range []string{"adduser", "nano", "vim-tiny", "curl"} {
+		report, err := d.Verify(pkgName)
+		if err != nil {
+			t.Fatalf("Verify(%s) failed: %v", pkgName, err)
+		}
+		if !report.OK() || len(report.Files) == 0 {
+			t.Errorf("Verify(%s) problems: %v", pkgName, report.Problems())
+		}
+	}
+
+	for pkg, err := range d.Packages() {
+		if err != nil {
+			t.Fatalf("Packages failed: %v", err)
+		}
+		if status, err := pkg.Status(); err != nil || status.State == StateNotInstalled {
+			continue
+		}
+		if report, err := d.VerifyPackage(pkg); err != nil || !report.OK() {
+			t.Errorf("VerifyPackage(%s) = %v, %v", pkg.Name(), report, err)
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(root, "usr/bin/vim.tiny"), []byte("tampered\n"), 0755); err != nil {
+		t.Fatalf("Failed to tamper a file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "etc/vim/vimrc.tiny"), []byte("set nocompatible\n"), 0644); err != nil {
+		t.Fatalf("Failed to modify a conffile: %v", err)
+	}
+	if err := os.Remove(filepath.Join(root, "usr/share/doc/vim-tiny/copyright")); err != nil {
+		t.Fatalf("Failed to remove a file: %v", err)
+	}
+
+	report, err := d.Verify("vim-tiny")
+	if err != nil {
+		t.Fatalf("Verify(vim-tiny) failed: %v", err)
+	}
+	var got []string
+	for _, file := range report.Problems() {
+		got = append(got, file.String())
+	}
+	want := []string{
+		"??5?????? c /etc/vim/vimrc.tiny",
+		"??5??????   /usr/bin/vim.tiny",
+		"missing     /usr/share/doc/vim-tiny/copyright",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Verify(vim-tiny) =\n%v\nwant\n%v", got, want)
+	}
+
+	if _, err := d.Verify("missing"); !errors.Is(err, ErrPackageNotInstalled) {
+		t.Errorf("Verify(missing) error = %v; want ErrPackageNotInstalled", err)
+	}
+}
@@ -1,119 +1,277 @@
 package dpkg
 
 import (
-	"regexp"
+	"fmt"
+	"math"
 	"strconv"
 	"strings"
 )
 
-// CompareVersions compares Debian package versions based on https://www.debian.org/doc/debian-policy/ch-controlfields.html#s-f-version
-func (dp *Dpkg) CompareVersions(v1, v2 string) int {
-	epoch1, version1, revision1 := splitVersion(v1)
-	epoch2, version2, revision2 := splitVersion(v2)
+// Version represents a Debian package version in the form [epoch:]upstream_version[-debian_revision]
+// https://www.debian.org/doc/debian-policy/ch-controlfields.html#s-f-version
+type Version struct {
+	epoch    int
+	upstream string
+	revision string
+}
+
+// ParseVersion parses and validates a Debian package version string
+// following the same rules as dpkg's parseversion
+// https://salsa.debian.org/dpkg-team/dpkg/-/blob/main/lib/dpkg/parsehelp.c
+func ParseVersion(s string) (Version, error) {
+	version := strings.TrimSpace(s)
+	if version == "" {
+		return Version{}, fmt.Errorf("%w: version string is empty", ErrInvalidVersion)
+	}
+	if strings.ContainsAny(version, " \t\n") {
+		return Version{}, fmt.Errorf("%w %q: version string has embedded spaces", ErrInvalidVersion, s)
+	}
+
+	var v Version
+
+	// Extract the epoch, which is everything before the first colon
+	if before, after, found := strings.Cut(version, ":"); found {
+		if before == "" {
+			return Version{}, fmt.Errorf("%w %q: epoch in version is empty", ErrInvalidVersion, s)
+		}
+		epoch, err := strconv.ParseInt(before, 10, 64)
+		if err != nil || !isDigits(before) {
+			return Version{}, fmt.Errorf("%w %q: epoch in version is not number", ErrInvalidVersion, s)
+		}
+		if epoch > math.MaxInt32 {
+			return Version{}, fmt.Errorf("%w %q: epoch in version is too big", ErrInvalidVersion, s)
+		}
+		v.epoch = int(epoch)
+		version = after
+	}
+
+	// Extract the revision, which is everything after the last hyphen
+	v.upstream = version
+	if i := strings.LastIndexByte(version, '-'); i >= 0 {
+		v.upstream = version[:i]
+		v.revision = version[i+1:]
+		if v.revision == "" {
+			return Version{}, fmt.Errorf("%w %q: revision number is empty", ErrInvalidVersion, s)
+		}
+	}
+
+	if v.upstream == "" {
+		return Version{}, fmt.Errorf("%w %q: version number is empty", ErrInvalidVersion, s)
+	}
+	if !isDigit(v.upstream[0]) {
+		return Version{}, fmt.Errorf("%w %q: version number does not start with digit", ErrInvalidVersion, s)
+	}
+
+	for _, c := range []byte(v.upstream) {
+		if !isAlnum(c) && !strings.ContainsRune(".-+~:", rune(c)) {
+			return Version{}, fmt.Errorf("%w %q: invalid character in version number", ErrInvalidVersion, s)
+		}
+	}
+	for _, c := range []byte(v.revision) {
+		if !isAlnum(c) && !strings.ContainsRune(".+~", rune(c)) {
+			return Version{}, fmt.Errorf("%w %q: invalid character in revision number", ErrInvalidVersion, s)
+		}
+	}
+
+	return v, nil
+}
+
+// Epoch returns the epoch of the version, 0 when it is not present
+func (v Version) Epoch() int {
+	return v.epoch
+}
+
+// Upstream returns the upstream part of the version
+func (v Version) Upstream() string {
+	return v.upstream
+}
+
+// Revision returns the Debian revision of the version, "" when it is not present
+func (v Version) Revision() string {
+	return v.revision
+}
+
+// String returns the version in its canonical textual form
+func (v Version) String() string {
+	var sb strings.Builder
+	if v.epoch != 0 {
+		sb.WriteString(strconv.Itoa(v.epoch))
+		sb.WriteByte(':')
+	}
+	sb.WriteString(v.upstream)
+	if v.revision != "" {
+		sb.WriteByte('-')
+		sb.WriteString(v.revision)
+	}
+	return sb.String()
+}
 
+// MarshalText implements the encoding.TextMarshaler interface
+func (v Version) MarshalText() ([]byte, error) {
+	return []byte(v.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface
+func (v *Version) UnmarshalText(text []byte) error {
+	parsed, err := ParseVersion(string(text))
+	if err != nil {
+		return err
+	}
+	*v = parsed
+	return nil
+}
+
+// Compare compares two versions and returns -1, 0 or 1 if v is respectively
+// lower than, equal to or greater than other
+func (v Version) Compare(other Version) int {
 	// Compare epochs
-	if epoch1 != epoch2 {
-		return epoch1 - epoch2
+	if v.epoch != other.epoch {
+		return sign(v.epoch - other.epoch)
 	}
 
-	// Compare main versions
-	if cmp := compareDebianVersion(version1, version2); cmp != 0 {
+	// Compare upstream versions
+	if cmp := compareDebianVersion(v.upstream, other.upstream); cmp != 0 {
 		return cmp
 	}
 
 	// Compare revisions
-	return compareDebianVersion(revision1, revision2)
+	return compareDebianVersion(v.revision, other.revision)
+}
+
+// CompareVersions compares Debian package versions based on https://www.debian.org/doc/debian-policy/ch-controlfields.html#s-f-version
+// Versions that fail validation are still compared, after being split the same way dpkg does
+func (dp *Dpkg) CompareVersions(v1, v2 string) int {
+	return lenientVersion(v1).Compare(lenientVersion(v2))
+}
+
+// lenientVersion parses a version, falling back to a plain split when it is not valid
+func lenientVersion(s string) Version {
+	if v, err := ParseVersion(s); err == nil {
+		return v
+	}
+	epoch, upstream, revision := splitVersion(strings.TrimSpace(s))
+	return Version{epoch: epoch, upstream: upstream, revision: revision}
 }
 
 // splitVersion splits a version into epoch, main version, and revision
 func splitVersion(version string) (epoch int, mainVersion, revision string) {
-	// Regex to capture epoch, main version, and revision
-	re := regexp.MustCompile(`^(?:(\d+):)?([^-]+)(?:-(.+))?$`)
-	matches := re.FindStringSubmatch(version)
-
-	// Extract epoch (if exists)
-	if matches[1] != "" {
-		epoch, _ = strconv.Atoi(matches[1])
-	} else {
-		epoch = 0 // Default epoch is 0
+	// The epoch is everything before the first colon
+	if before, after, found := strings.Cut(version, ":"); found {
+		epoch, _ = strconv.Atoi(before)
+		version = after
 	}
 
-	// Extract main version and revision
-	mainVersion = matches[2]
-	revision = matches[3]
+	// The revision is everything after the last hyphen
+	mainVersion = version
+	if i := strings.LastIndexByte(version, '-'); i >= 0 {
+		mainVersion = version[:i]
+		revision = version[i+1:]
+	}
 
 	return
 }
 
 // compareDebianVersion compares parts of the version (main version or revision)
+// using dpkg's verrevcmp algorithm
 func compareDebianVersion(v1, v2 string) int {
-	// Split versions into parts (numbers and strings)
-	parts1 := splitVersionParts(v1)
-	parts2 := splitVersionParts(v2)
+	a, b := 0, 0
 
-	// Compare each part
-	for i := 0; i < len(parts1) || i < len(parts2); i++ {
-		part1 := getPart(parts1, i)
-		part2 := getPart(parts2, i)
+	for a < len(v1) || b < len(v2) {
+		firstDiff := 0
 
-		if part1 == part2 {
-			continue
+		// Compare the non-digit prefixes character by character
+		for (a < len(v1) && !isDigit(v1[a])) || (b < len(v2) && !isDigit(v2[b])) {
+			ac := charOrder(v1, a)
+			bc := charOrder(v2, b)
+			if ac != bc {
+				return sign(ac - bc)
+			}
+			a++
+			b++
 		}
 
-		// Compare numerically if both parts are numbers
-		num1, err1 := strconv.Atoi(part1)
-		num2, err2 := strconv.Atoi(part2)
-		if err1 == nil && err2 == nil {
-			if num1 < num2 {
-				return -1
-			} else if num1 > num2 {
-				return 1
-			}
-		} else {
-			// Compare lexicographically
-			if part1 < part2 {
-				return -1
-			} else if part1 > part2 {
-				return 1
+		// Skip leading zeros
+		for a < len(v1) && v1[a] == '0' {
+			a++
+		}
+		for b < len(v2) && v2[b] == '0' {
+			b++
+		}
+
+		// Compare the numeric parts, the longest one wins
+		for a < len(v1) && isDigit(v1[a]) && b < len(v2) && isDigit(v2[b]) {
+			if firstDiff == 0 {
+				firstDiff = int(v1[a]) - int(v2[b])
 			}
+			a++
+			b++
+		}
+		if a < len(v1) && isDigit(v1[a]) {
+			return 1
+		}
+		if b < len(v2) && isDigit(v2[b]) {
+			return -1
+		}
+		if firstDiff != 0 {
+			return sign(firstDiff)
 		}
 	}
 
 	return 0
 }
 
-// splitVersionParts splits a version into parts (numbers and strings)
-func splitVersionParts(version string) []string {
-	var parts []string
-	var current strings.Builder
-
-	for _, char := range version {
-		if char >= '0' && char <= '9' {
-			// If it's a digit, add to the current number
-			current.WriteRune(char)
-		} else {
-			// If it's not a digit, finalize the current number (if any)
-			if current.Len() > 0 {
-				parts = append(parts, current.String())
-				current.Reset()
-			}
-			// Add the non-numeric character as a separate part
-			parts = append(parts, string(char))
-		}
+// charOrder returns the sort weight of the character at index i, where
+// '~' sorts before everything, even the end of the string, and letters
+// sort before non-letters
+func charOrder(s string, i int) int {
+	if i >= len(s) {
+		return 0
 	}
-
-	// Add the last number (if any)
-	if current.Len() > 0 {
-		parts = append(parts, current.String())
+	c := s[i]
+	switch {
+	case isDigit(c):
+		return 0
+	case isAlpha(c):
+		return int(c)
+	case c == '~':
+		return -1
+	default:
+		return int(c) + 256
 	}
+}
 
-	return parts
+// isDigit checks if the character is an ASCII digit
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
 }
 
-// getPart retrieves a part from a slice, returning "" if the index is invalid
-func getPart(parts []string, index int) string {
-	if index < len(parts) {
-		return parts[index]
+// isAlpha checks if the character is an ASCII letter
+func isAlpha(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// isAlnum checks if the character is an ASCII letter or digit
+func isAlnum(c byte) bool {
+	return isDigit(c) || isAlpha(c)
+}
+
+// isDigits checks if the string is only made of ASCII digits
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !isDigit(s[i]) {
+			return false
+		}
 	}
-	return ""
+	return s != ""
+}
+
+// sign reduces a comparison result to -1, 0 or 1
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
 }
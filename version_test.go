@@ -1,6 +1,7 @@
 package dpkg
 
 import (
+	"errors"
 	"testing"
 )
 
@@ -28,6 +29,12 @@ func TestCompareVersions(t *testing.T) {
 		{"7.0-122+2etch5", "7.0-122+1etch5", 1},
 		{"7.0-122+1etch6", "7.0-122+1etch5", 1},
 		{"7.0-122+1etch5", "7.0-122+2etch5", -1},
+		{"1.0~rc1", "1.0", -1},
+		{"1.0~rc1", "1.0~rc1~1", 1},
+		{"1.0a", "1.0+", -1},
+		{"1.0-1-1", "1.0-1-2", -1},
+		{"1.0-2", "1.0-1-3", -1},
+		{"1.01", "1.1", 0},
 	}
 
 	for _, tt := range tests {
@@ -53,6 +60,7 @@ func TestSplitVersion(t *testing.T) {
 		{"1.0-1", 0, "1.0", "1"},
 		{"1.0", 0, "1.0", ""},
 		{"2:2.0", 2, "2.0", ""},
+		{"1.0-2-3", 0, "1.0-2", "3"},
 	}
 
 	for _, tt := range tests {
@@ -79,6 +87,12 @@ func TestCompareDebianVersion(t *testing.T) {
 		{"1.0b", "1.0a", 1},
 		{"1.0-1", "1.0-2", -1},
 		{"1.0-2", "1.0-1", 1},
+		{"1.0~", "1.0", -1},
+		{"1.0~~", "1.0~", -1},
+		{"1.0", "1.0a", -1},
+		{"1.0a", "1.0.", -1},
+		{"1.002", "1.2", 0},
+		{"10", "9", 1},
 	}
 
 	for _, tt := range tests {
@@ -91,60 +105,115 @@ func TestCompareDebianVersion(t *testing.T) {
 	}
 }
 
-// TestSplitVersionParts tests the splitVersionParts function
-func TestSplitVersionParts(t *testing.T) {
+// TestParseVersion tests the ParseVersion function
+func TestParseVersion(t *testing.T) {
 	tests := []struct {
-		version string
-		want    []string
+		version      string
+		wantEpoch    int
+		wantUpstream string
+		wantRevision string
+		wantErr      bool
 	}{
-		{"1.0", []string{"1", ".", "0"}},
-		{"1.0-1", []string{"1", ".", "0", "-", "1"}},
-		{"1.0a", []string{"1", ".", "0", "a"}},
-		{"1.0~beta1", []string{"1", ".", "0", "~", "b", "e", "t", "a", "1"}},
+		{"1.0", 0, "1.0", "", false},
+		{"2:9.1.1113-1", 2, "9.1.1113", "1", false},
+		{"1:7.0-122+1etch5", 1, "7.0", "122+1etch5", false},
+		{"1.0-2-3", 0, "1.0-2", "3", false},
+		{"1:2:3.0", 1, "2:3.0", "", false},
+		{" 1.0~rc1-1 ", 0, "1.0~rc1", "1", false},
+		{"", 0, "", "", true},
+		{"1.0 1", 0, "", "", true},
+		{":1.0", 0, "", "", true},
+		{"a:1.0", 0, "", "", true},
+		{"-1:1.0", 0, "", "", true},
+		{"99999999999:1.0", 0, "", "", true},
+		{"1.0-", 0, "", "", true},
+		{"-1", 0, "", "", true},
+		{"a1.0", 0, "", "", true},
+		{"1.0_1", 0, "", "", true},
+		{"1.0-1:2", 0, "", "", true},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.version, func(t *testing.T) {
-			got := splitVersionParts(tt.version)
-			if !equalStringSlices(got, tt.want) {
-				t.Errorf("splitVersionParts(%q) = %v; want %v", tt.version, got, tt.want)
+			v, err := ParseVersion(tt.version)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidVersion) {
+					t.Errorf("ParseVersion(%q) error = %v; want ErrInvalidVersion", tt.version, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseVersion(%q) unexpected error: %v", tt.version, err)
+			}
+			if v.Epoch() != tt.wantEpoch || v.Upstream() != tt.wantUpstream || v.Revision() != tt.wantRevision {
+				t.Errorf("ParseVersion(%q) = (%d, %q, %q); want (%d, %q, %q)",
+					tt.version, v.Epoch(), v.Upstream(), v.Revision(), tt.wantEpoch, tt.wantUpstream, tt.wantRevision)
 			}
 		})
 	}
 }
 
-// TestGetPart tests the getPart function
-func TestGetPart(t *testing.T) {
+// TestVersionString tests the String method of Version
+func TestVersionString(t *testing.T) {
 	tests := []struct {
-		parts []string
-		index int
-		want  string
+		version string
+		want    string
 	}{
-		{[]string{"1", ".", "0"}, 0, "1"},
-		{[]string{"1", ".", "0"}, 1, "."},
-		{[]string{"1", ".", "0"}, 2, "0"},
-		{[]string{"1", ".", "0"}, 3, ""},
+		{"1.0", "1.0"},
+		{"0:1.0-1", "1.0-1"},
+		{"2:9.1.1113-1", "2:9.1.1113-1"},
+		{" 1.0~rc1 ", "1.0~rc1"},
 	}
 
 	for _, tt := range tests {
-		t.Run("", func(t *testing.T) {
-			got := getPart(tt.parts, tt.index)
-			if got != tt.want {
-				t.Errorf("getPart(%v, %d) = %q; want %q", tt.parts, tt.index, got, tt.want)
+		t.Run(tt.version, func(t *testing.T) {
+			v, err := ParseVersion(tt.version)
+			if err != nil {
+				t.Fatalf("ParseVersion(%q) unexpected error: %v", tt.version, err)
+			}
+			if got := v.String(); got != tt.want {
+				t.Errorf("Version(%q).String() = %q; want %q", tt.version, got, tt.want)
 			}
 		})
 	}
 }
 
-// Helper function to compare two slices of strings
-func equalStringSlices(a, b []string) bool {
-	if len(a) != len(b) {
-		return false
+// TestVersionCompare tests the Compare method of Version
+func TestVersionCompare(t *testing.T) {
+	// Each version is strictly greater than the previous one
+	ordered := []string{
+		"0.9",
+		"1.0~~",
+		"1.0~~a",
+		"1.0~",
+		"1.0~rc1",
+		"1.0",
+		"1.0-0.1",
+		"1.0-1~bpo1",
+		"1.0-1",
+		"1.0a",
+		"1.0+dfsg",
+		"1.0.1",
+		"1.2",
+		"1.10",
+		"1:0.1",
 	}
-	for i := range a {
-		if a[i] != b[i] {
-			return false
+
+	for i := range ordered {
+		for j := range ordered {
+			v1, err := ParseVersion(ordered[i])
+			if err != nil {
+				t.Fatalf("ParseVersion(%q) unexpected error: %v", ordered[i], err)
+			}
+			v2, err := ParseVersion(ordered[j])
+			if err != nil {
+				t.Fatalf("ParseVersion(%q) unexpected error: %v", ordered[j], err)
+			}
+
+			want := sign(i - j)
+			if got := v1.Compare(v2); got != want {
+				t.Errorf("Compare(%q, %q) = %d; want %d", ordered[i], ordered[j], got, want)
+			}
 		}
 	}
-	return true
 }
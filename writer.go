@@ -0,0 +1,87 @@
+package dpkg
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ParagraphWriter writes deb822 paragraphs, such as control files or the stanzas of a
+// status or Packages file, separating them with blank lines. It is the counterpart of
+// ParagraphReader, and round-trips the paragraphs it reads.
+type ParagraphWriter struct {
+	// Order lists the fields written first, in that order; the remaining
+	// fields are written afterwards in their original order
+	Order []string
+	// Terminate ends every paragraph with a blank line, as dpkg does in status and
+	// Packages files, instead of only separating them
+	Terminate bool
+
+	w     io.Writer
+	count int
+}
+
+// NewParagraphWriter creates a new ParagraphWriter writing to w
+func NewParagraphWriter(w io.Writer) *ParagraphWriter {
+	return &ParagraphWriter{
+		w: w,
+	}
+}
+
+// Write writes a paragraph; fields that were parsed and not modified are written
+// exactly as they were read, while the others are folded into continuation lines
+func (pw *ParagraphWriter) Write(p *Paragraph) error {
+	fields := pw.sortFields(p)
+
+	// Validate the fields before writing anything
+	for _, f := range fields {
+		if f.raw == "" && !isValidFieldName(f.Name) {
+			return fmt.Errorf("%w: invalid field name %q", ErrInvalidField, f.Name)
+		}
+	}
+
+	var sb strings.Builder
+	if pw.count > 0 && !pw.Terminate {
+		sb.WriteByte('\n')
+	}
+	for _, f := range fields {
+		sb.WriteString(f.String())
+	}
+	if pw.Terminate {
+		sb.WriteByte('\n')
+	}
+
+	if _, err := io.WriteString(pw.w, sb.String()); err != nil {
+		return fmt.Errorf("go-apt/dpkg: failed to write paragraph: %w", err)
+	}
+	pw.count++
+
+	return nil
+}
+
+// sortFields returns the fields of the paragraph in the configured order
+func (pw *ParagraphWriter) sortFields(p *Paragraph) []Field {
+	if len(pw.Order) == 0 {
+		return p.fields
+	}
+
+	fields := make([]Field, 0, len(p.fields))
+	written := make([]bool, len(p.fields))
+
+	// Fields listed in Order first
+	for _, name := range pw.Order {
+		if i := p.index(name); i >= 0 && !written[i] {
+			fields = append(fields, p.fields[i])
+			written[i] = true
+		}
+	}
+
+	// Remaining fields in their original order
+	for i, f := range p.fields {
+		if !written[i] {
+			fields = append(fields, f)
+		}
+	}
+
+	return fields
+}
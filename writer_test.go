@@ -0,0 +1,110 @@
+package dpkg
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+// TestParagraphWriterRoundTrip tests that parsed files are written back byte-identical
+func TestParagraphWriterRoundTrip(t *testing.T) {
+	tests := []string{
+		"testdata/control/control-1",
+		"testdata/control/control-2",
+		"testdata/status",
+	}
+
+	for _, filePath := range tests {
+		t.Run(filePath, func(t *testing.T) {
+			content, err := os.ReadFile(filePath)
+			if err != nil {
+				t.Fatalf("Failed to read file %s: %v", filePath, err)
+			}
+
+			var buffer bytes.Buffer
+			writer := NewParagraphWriter(&buffer)
+			for pkg, err := range NewParagraphReader(bytes.NewReader(content)).Packages() {
+				if err != nil {
+					t.Fatalf("Failed to read paragraph from %s: %v", filePath, err)
+				}
+				if err := writer.Write(&pkg.Paragraph); err != nil {
+					t.Fatalf("Failed to write paragraph from %s: %v", filePath, err)
+				}
+			}
+
+			if buffer.String() != string(content) {
+				t.Errorf("Written %s is not byte-identical:\n%s\nwant:\n%s", filePath, buffer.String(), content)
+			}
+		})
+	}
+}
+
+// TestParagraphWriterFolding tests the continuation handling of modified fields
+func TestParagraphWriterFolding(t *testing.T) {
+	var p Paragraph
+	p.Set("Package", "foo")
+	p.Set("Description", "short summary\nFirst paragraph.\n\n  verbatim line\n \nLast paragraph.\n")
+
+	var buffer bytes.Buffer
+	if err := NewParagraphWriter(&buffer).Write(&p); err != nil {
+		t.Fatalf("Failed to write paragraph: %v", err)
+	}
+
+	want := "Package: foo\nDescription: short summary\n First paragraph.\n .\n  verbatim line\n .\n Last paragraph.\n"
+	if buffer.String() != want {
+		t.Errorf("Written paragraph = %q; want %q", buffer.String(), want)
+	}
+
+	// The written paragraph can be parsed back
+	parsed, err := parseParagraph(buffer.String())
+	if err != nil {
+		t.Fatalf("Failed to parse written paragraph: %v", err)
+	}
+	if got := parsed.Get("Description"); got != "short summary\n First paragraph.\n .\n  verbatim line\n .\n Last paragraph." {
+		t.Errorf("Parsed Description = %q", got)
+	}
+}
+
+// TestParagraphWriterOrder tests the configurable field ordering and paragraph terminators
+func TestParagraphWriterOrder(t *testing.T) {
+	content := "Version: 1.0\nArchitecture: all\npackage: foo\n\nArchitecture: amd64\nPackage: bar\n"
+
+	var buffer bytes.Buffer
+	writer := NewParagraphWriter(&buffer)
+	writer.Order = []string{"Package", "Version"}
+	writer.Terminate = true
+
+	for pkg, err := range NewParagraphReader(strings.NewReader(content)).Packages() {
+		if err != nil {
+			t.Fatalf("Failed to read paragraph: %v", err)
+		}
+		if err := writer.Write(&pkg.Paragraph); err != nil {
+			t.Fatalf("Failed to write paragraph: %v", err)
+		}
+	}
+
+	want := "package: foo\nVersion: 1.0\nArchitecture: all\n\nPackage: bar\nArchitecture: amd64\n\n"
+	if buffer.String() != want {
+		t.Errorf("Written paragraphs = %q; want %q", buffer.String(), want)
+	}
+}
+
+// TestParagraphWriterInvalidField tests that invalid field names are rejected
+func TestParagraphWriterInvalidField(t *testing.T) {
+	for _, name := range []string{"", "Bad Name", "Bad:Name", "#Comment", "-Field"} {
+		var p Paragraph
+		p.Set("Package", "foo")
+		p.Set(name, "value")
+
+		var buffer bytes.Buffer
+		err := NewParagraphWriter(&buffer).Write(&p)
+		if !errors.Is(err, ErrInvalidField) {
+			t.Errorf("Write with field %q error = %v; want ErrInvalidField", name, err)
+		}
+		if buffer.Len() != 0 {
+			t.Errorf("Write with field %q wrote %q; want nothing", name, buffer.String())
+		}
+	}
+}